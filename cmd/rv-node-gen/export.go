@@ -0,0 +1,36 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/bryanl/rv-node-gen/pkg/rvnodegen"
+)
+
+// runExport prints the graph for the namespaces in args in a graph format.
+func runExport(o options, nodeOptions []rvnodegen.Option, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	sf := addScopeFlags(fs)
+	format := fs.String("format", "mermaid", "graph format")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	encoder, err := rvnodegen.GraphEncoderForFormat(*format)
+	if err != nil {
+		return err
+	}
+
+	lister, err := newLister(o)
+	if err != nil {
+		return err
+	}
+
+	nodes, err := rvnodegen.NewNodeBuilder(lister, nodeOptions...).BuildScope(sf.Scope())
+	if err != nil {
+		return fmt.Errorf("build nodes: %w", err)
+	}
+
+	return encoder.Encode(os.Stdout, nodes)
+}
@@ -3,43 +3,138 @@ package main
 import (
 	"context"
 	"flag"
+	"fmt"
 	golog "log"
 	"os"
-	"path/filepath"
-
-	"k8s.io/client-go/util/homedir"
+	"strings"
 
 	"github.com/bryanl/rv-node-gen/internal/log"
 	"github.com/bryanl/rv-node-gen/pkg/rvnodegen"
 )
 
 type options struct {
-	kubeConfigPath string
-	httpAddr       string
+	kubeConfig        rvnodegen.KubeConfig
+	manifests         string
+	snapshot          string
+	clusters          string
+	httpAddr          string
+	groupApplications bool
+	groupHelmReleases bool
+	expandRevisions   bool
+	argoCDInstance    string
+	applicationLabels rvnodegen.ApplicationLabelKeys
 }
 
 func main() {
 	o := options{}
 
-	if home := homedir.HomeDir(); home != "" {
-		flag.StringVar(&o.kubeConfigPath, "kubeconfig", filepath.Join(home, ".kube", "config"), "(optional) absolute path to the kubeconfig file")
-	} else {
-		flag.StringVar(&o.kubeConfigPath, "kubeconfig", "", "absolute path to the kubeconfig file")
-	}
+	flag.StringVar(&o.kubeConfig.Path, "kubeconfig", "", "(optional) path to the kubeconfig file, otherwise KUBECONFIG or ~/.kube/config are used")
+	flag.StringVar(&o.kubeConfig.Context, "context", "", "kubeconfig context to use")
+	flag.StringVar(&o.kubeConfig.Cluster, "cluster", "", "kubeconfig cluster to use")
+	flag.StringVar(&o.kubeConfig.User, "user", "", "kubeconfig user to use")
+	flag.StringVar(&o.kubeConfig.Namespace, "namespace", "", "namespace to use when a request does not have one")
+
+	flag.StringVar(&o.manifests, "manifests", "", "comma separated manifest files or directories to read instead of a cluster (- reads stdin)")
+
+	flag.StringVar(&o.snapshot, "snapshot", "", "cluster snapshot archive to read instead of a cluster")
+
+	flag.StringVar(&o.clusters, "clusters", "", "comma separated kube config contexts to serve, or * to serve all contexts")
 
 	flag.StringVar(&o.httpAddr, "addr", ":8181", "HTTP listen address")
+
+	flag.BoolVar(&o.groupHelmReleases, "group-helm-releases", false, "group objects by Helm release")
+
+	flag.BoolVar(&o.expandRevisions, "expand-revision-history", false, "show scaled down deployment revisions")
+
+	flag.StringVar(&o.argoCDInstance, "argocd-instance-label", "", "(optional) label key Argo CD tracks resources with, otherwise only the tracking id annotation is used")
+
+	labelKeys := rvnodegen.DefaultApplicationLabelKeys()
+	flag.BoolVar(&o.groupApplications, "group-applications", false, "group objects into applications using labels")
+	flag.StringVar(&o.applicationLabels.PartOf, "application-part-of-label", labelKeys.PartOf, "label key for the application an object is part of")
+	flag.StringVar(&o.applicationLabels.Instance, "application-instance-label", labelKeys.Instance, "label key for the application instance")
+	flag.StringVar(&o.applicationLabels.Name, "application-name-label", labelKeys.Name, "label key for the application name")
+	flag.StringVar(&o.applicationLabels.Component, "application-component-label", labelKeys.Component, "label key for the application component")
 	flag.Parse()
 
-	if err := run(o); err != nil {
+	if err := run(o, flag.Args()); err != nil {
 		golog.Printf(err.Error())
 		os.Exit(1)
 	}
 }
 
-func run(o options) error {
+func run(o options, args []string) error {
 	logger := log.New()
 	ctx := log.With(context.Background(), logger)
 
-	server := rvnodegen.NewServer(o.kubeConfigPath, o.httpAddr)
+	nodeOptions := []rvnodegen.Option{rvnodegen.Logger(logger)}
+	if o.groupHelmReleases {
+		nodeOptions = append(nodeOptions, rvnodegen.HelmReleaseGrouping())
+	}
+	if o.expandRevisions {
+		nodeOptions = append(nodeOptions, rvnodegen.ExpandRevisionHistory())
+	}
+	if o.argoCDInstance != "" {
+		nodeOptions = append(nodeOptions, rvnodegen.ArgoCDInstanceLabel(o.argoCDInstance))
+	}
+	if o.groupApplications {
+		nodeOptions = append(nodeOptions, rvnodegen.ApplicationGrouping(o.applicationLabels))
+	}
+
+	namespace, err := o.kubeConfig.DefaultNamespace()
+	if err != nil {
+		return err
+	}
+	nodeOptions = append(nodeOptions, rvnodegen.DefaultScopeNamespace(namespace))
+
+	if len(args) > 0 {
+		switch args[0] {
+		case "export":
+			return runExport(o, nodeOptions, args[1:])
+		case "orphans":
+			return runOrphans(o, nodeOptions, args[1:])
+		case "snapshot":
+			return runSnapshot(o, nodeOptions, args[1:])
+		default:
+			return fmt.Errorf("unknown command %q", args[0])
+		}
+	}
+
+	server := rvnodegen.NewServer(o.kubeConfig, o.httpAddr, nodeOptions...)
+	switch {
+	case o.manifests != "" || o.snapshot != "":
+		lister, err := newLister(o)
+		if err != nil {
+			return err
+		}
+		server = rvnodegen.NewListerServer(lister, o.httpAddr, nodeOptions...)
+	case o.clusters == "*":
+		server = rvnodegen.NewMultiClusterServer(o.kubeConfig, nil, o.httpAddr, nodeOptions...)
+	case o.clusters != "":
+		server = rvnodegen.NewMultiClusterServer(o.kubeConfig, strings.Split(o.clusters, ","), o.httpAddr, nodeOptions...)
+	}
+
 	return server.Run(ctx)
 }
+
+// newLister creates a lister for the manifests or snapshot if they are set, otherwise for the
+// cluster.
+func newLister(o options) (rvnodegen.Lister, error) {
+	if o.snapshot != "" {
+		snapshot, err := rvnodegen.ReadClusterSnapshotFile(o.snapshot)
+		if err != nil {
+			return nil, fmt.Errorf("read snapshot: %w", err)
+		}
+		return snapshot.Lister(), nil
+	}
+
+	if o.manifests == "" {
+		return rvnodegen.NewClusterLister(o.kubeConfig)
+	}
+
+	objects, err := rvnodegen.ReadManifests(strings.Split(o.manifests, ",")...)
+	if err != nil {
+		return nil, fmt.Errorf("read manifests: %w", err)
+	}
+
+	return rvnodegen.NewManifestLister(objects...)
+}
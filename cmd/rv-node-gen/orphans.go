@@ -0,0 +1,42 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/bryanl/rv-node-gen/pkg/rvnodegen"
+)
+
+// runOrphans prints the orphan report for the namespaces in args as JSON.
+func runOrphans(o options, nodeOptions []rvnodegen.Option, args []string) error {
+	fs := flag.NewFlagSet("orphans", flag.ExitOnError)
+	sf := addScopeFlags(fs)
+	kinds := fs.String("kind", "", "comma separated kinds to check, otherwise every kind is checked")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	lister, err := newLister(o)
+	if err != nil {
+		return err
+	}
+
+	var orphanKinds []string
+	for _, kind := range strings.Split(*kinds, ",") {
+		if kind = strings.TrimSpace(kind); kind != "" {
+			orphanKinds = append(orphanKinds, kind)
+		}
+	}
+
+	report, err := rvnodegen.NewNodeBuilder(lister, nodeOptions...).Orphans(sf.Scope(), orphanKinds...)
+	if err != nil {
+		return fmt.Errorf("find orphans: %w", err)
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"flag"
+	"strings"
+
+	"github.com/bryanl/rv-node-gen/pkg/rvnodegen"
+)
+
+// scopeFlags are the flags selecting the namespaces a command covers.
+type scopeFlags struct {
+	namespaces    string
+	allNamespaces bool
+}
+
+func addScopeFlags(fs *flag.FlagSet) *scopeFlags {
+	sf := &scopeFlags{}
+	fs.StringVar(&sf.namespaces, "namespace", "", "comma separated namespaces, otherwise the kubeconfig namespace is used")
+	fs.BoolVar(&sf.allNamespaces, "all-namespaces", false, "use all namespaces")
+	return sf
+}
+
+// Scope returns the scope selected by the flags.
+func (sf *scopeFlags) Scope() rvnodegen.Scope {
+	scope := rvnodegen.Scope{AllNamespaces: sf.allNamespaces}
+	for _, namespace := range strings.Split(sf.namespaces, ",") {
+		if namespace = strings.TrimSpace(namespace); namespace != "" {
+			scope.Namespaces = append(scope.Namespaces, namespace)
+		}
+	}
+	return scope
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/bryanl/rv-node-gen/pkg/rvnodegen"
+)
+
+// runSnapshot captures a snapshot of the cluster's objects to a tar archive.
+func runSnapshot(o options, nodeOptions []rvnodegen.Option, args []string) error {
+	fs := flag.NewFlagSet("snapshot", flag.ExitOnError)
+	output := fs.String("o", "-", "snapshot archive to write (- writes stdout)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	informerManager, err := rvnodegen.NewClusterInformerManager(o.kubeConfig, nodeOptions...)
+	if err != nil {
+		return err
+	}
+	defer informerManager.Stop()
+
+	var w io.Writer = os.Stdout
+	if *output != "-" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if err := informerManager.WriteSnapshot(w); err != nil {
+		return fmt.Errorf("write snapshot: %w", err)
+	}
+
+	return nil
+}
@@ -12,8 +12,8 @@ require (
 	github.com/gorilla/websocket v1.4.2
 	go.uber.org/multierr v1.6.0 // indirect
 	go.uber.org/zap v1.16.0
+	k8s.io/api v0.20.1
 	k8s.io/apimachinery v0.20.1
 	k8s.io/client-go v0.20.1
 	k8s.io/utils v0.0.0-20201110183641-67b214c5f920
-
 )
@@ -33,6 +33,17 @@ func New() *Logger {
 	return l
 }
 
+// Nop creates an instance of Logger that discards its output.
+func Nop() *Logger {
+	zapLog := zap.NewNop()
+
+	l := &Logger{
+		zapLog: zapLog,
+		Logger: zapr.NewLogger(zapLog),
+	}
+	return l
+}
+
 // Sync syncs the logger.
 func (l *Logger) Sync() {
 	_ = l.zapLog.Sync()
@@ -0,0 +1,270 @@
+package rvnodegen
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	authorizationv1client "k8s.io/client-go/kubernetes/typed/authorization/v1"
+)
+
+// informerVerbs are the verbs an informer needs.
+var informerVerbs = []string{"list", "watch"}
+
+// maxConcurrentAccessReviews caps the namespaces checked at once for a resource.
+const maxConcurrentAccessReviews = 10
+
+// SkippedResource is a resource the informer manager does not read across namespaces because
+// the user can't list and watch it, or its cache did not sync.
+type SkippedResource struct {
+	// Group is the resource's API group.
+	Group string `json:"group,omitempty"`
+	// Version is the resource's API version.
+	Version string `json:"version"`
+	// Kind is the resource's kind.
+	Kind string `json:"kind"`
+	// Resource is the resource's name.
+	Resource string `json:"resource"`
+	// Namespaces are the namespaces the resource is read in instead. It is empty if the resource
+	// is not read at all.
+	Namespaces []string `json:"namespaces,omitempty"`
+	// Reason is why the resource was skipped.
+	Reason string `json:"reason"`
+}
+
+// discoveredResource is a resource served by a cluster.
+type discoveredResource struct {
+	gvk        schema.GroupVersionKind
+	gvr        schema.GroupVersionResource
+	namespaced bool
+}
+
+// resourceAccess is where a resource can be read.
+type resourceAccess struct {
+	// clusterWide is true if the resource can be read across namespaces.
+	clusterWide bool
+	// namespaces are the namespaces the resource can be read in if it can't be read across
+	// namespaces.
+	namespaces []string
+}
+
+// accessReviewer checks the current user's access with self subject access and rules reviews.
+type accessReviewer struct {
+	client authorizationv1client.AuthorizationV1Interface
+
+	mu    sync.Mutex
+	rules map[string]authorizationv1.SubjectRulesReviewStatus
+}
+
+func newAccessReviewer(client authorizationv1client.AuthorizationV1Interface) *accessReviewer {
+	ar := &accessReviewer{
+		client: client,
+		rules:  map[string]authorizationv1.SubjectRulesReviewStatus{},
+	}
+	return ar
+}
+
+// review returns the access to resources. Resources that can't be read across namespaces are
+// checked in namespaces, and are skipped where they can't be read.
+func (ar *accessReviewer) review(ctx context.Context, resources []discoveredResource, namespaces []string) (map[schema.GroupVersionKind]resourceAccess, []SkippedResource, error) {
+	access := map[schema.GroupVersionKind]resourceAccess{}
+
+	// users who can read everything don't need a review for each resource.
+	all, err := ar.canRead(ctx, schema.GroupResource{Group: "*", Resource: "*"}, "")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var skipped []SkippedResource
+
+	for _, resource := range resources {
+		if all {
+			access[resource.gvk] = resourceAccess{clusterWide: true}
+			continue
+		}
+
+		clusterWide, err := ar.canRead(ctx, resource.gvr.GroupResource(), "")
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if clusterWide {
+			access[resource.gvk] = resourceAccess{clusterWide: true}
+			continue
+		}
+
+		sr := SkippedResource{
+			Group:    resource.gvk.Group,
+			Version:  resource.gvk.Version,
+			Kind:     resource.gvk.Kind,
+			Resource: resource.gvr.Resource,
+			Reason:   "cannot list and watch across namespaces",
+		}
+
+		if !resource.namespaced {
+			skipped = append(skipped, sr)
+			continue
+		}
+
+		readable, err := ar.readableNamespaces(ctx, resource.gvr.GroupResource(), namespaces)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if len(readable) > 0 {
+			access[resource.gvk] = resourceAccess{namespaces: readable}
+			sr.Namespaces = readable
+			sr.Reason = "cannot list and watch across namespaces, reading namespaces " + strings.Join(readable, ", ")
+		}
+
+		skipped = append(skipped, sr)
+	}
+
+	sortSkippedResources(skipped)
+
+	return access, skipped, nil
+}
+
+func sortSkippedResources(skipped []SkippedResource) {
+	sort.Slice(skipped, func(i, j int) bool {
+		return skipped[i].Group+"/"+skipped[i].Resource < skipped[j].Group+"/"+skipped[j].Resource
+	})
+}
+
+// readableNamespaces returns the namespaces a resource can be read in. Namespaces are checked
+// concurrently, since incomplete rules fall back to an access review for each namespace.
+func (ar *accessReviewer) readableNamespaces(ctx context.Context, resource schema.GroupResource, namespaces []string) ([]string, error) {
+	ok := make([]bool, len(namespaces))
+	errs := make([]error, len(namespaces))
+
+	sem := make(chan struct{}, maxConcurrentAccessReviews)
+	var wg sync.WaitGroup
+
+	for i := range namespaces {
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			ok[i], errs[i] = ar.canReadInNamespace(ctx, resource, namespaces[i])
+		}(i)
+	}
+
+	wg.Wait()
+
+	var readable []string
+	for i, namespace := range namespaces {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+
+		if ok[i] {
+			readable = append(readable, namespace)
+		}
+	}
+
+	return readable, nil
+}
+
+// canRead checks if the user can list and watch a resource with self subject access reviews.
+// An empty namespace checks across namespaces.
+func (ar *accessReviewer) canRead(ctx context.Context, resource schema.GroupResource, namespace string) (bool, error) {
+	for _, verb := range informerVerbs {
+		review := &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: &authorizationv1.ResourceAttributes{
+					Namespace: namespace,
+					Verb:      verb,
+					Group:     resource.Group,
+					Resource:  resource.Resource,
+				},
+			},
+		}
+
+		review, err := ar.client.SelfSubjectAccessReviews().Create(ctx, review, metav1.CreateOptions{})
+		if err != nil {
+			return false, fmt.Errorf("review access to %s: %w", resource, err)
+		}
+
+		if !review.Status.Allowed {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// canReadInNamespace checks if the user can list and watch a resource in a namespace. It uses the
+// namespace's rules, and falls back to access reviews if the rules are incomplete.
+func (ar *accessReviewer) canReadInNamespace(ctx context.Context, resource schema.GroupResource, namespace string) (bool, error) {
+	rules, err := ar.namespaceRules(ctx, namespace)
+	if err != nil {
+		return false, err
+	}
+
+	for _, verb := range informerVerbs {
+		if !rulesAllow(rules.ResourceRules, resource, verb) {
+			if rules.Incomplete {
+				return ar.canRead(ctx, resource, namespace)
+			}
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// namespaceRules returns the user's rules in a namespace. Rules are reviewed once per namespace.
+func (ar *accessReviewer) namespaceRules(ctx context.Context, namespace string) (authorizationv1.SubjectRulesReviewStatus, error) {
+	ar.mu.Lock()
+	rules, ok := ar.rules[namespace]
+	ar.mu.Unlock()
+
+	if ok {
+		return rules, nil
+	}
+
+	review := &authorizationv1.SelfSubjectRulesReview{
+		Spec: authorizationv1.SelfSubjectRulesReviewSpec{Namespace: namespace},
+	}
+
+	review, err := ar.client.SelfSubjectRulesReviews().Create(ctx, review, metav1.CreateOptions{})
+	if err != nil {
+		return authorizationv1.SubjectRulesReviewStatus{}, fmt.Errorf("review rules in namespace %s: %w", namespace, err)
+	}
+
+	ar.mu.Lock()
+	ar.rules[namespace] = review.Status
+	ar.mu.Unlock()
+
+	return review.Status, nil
+}
+
+// rulesAllow returns true if a rule allows a verb on every object of a resource.
+func rulesAllow(rules []authorizationv1.ResourceRule, resource schema.GroupResource, verb string) bool {
+	for _, rule := range rules {
+		if len(rule.ResourceNames) > 0 {
+			continue
+		}
+
+		if ruleMatches(rule.Verbs, verb) && ruleMatches(rule.APIGroups, resource.Group) &&
+			ruleMatches(rule.Resources, resource.Resource) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func ruleMatches(values []string, s string) bool {
+	return stringsIncludes("*", values) || stringsIncludes(s, values)
+}
@@ -21,13 +21,30 @@ const (
 
 // API is the node gen api
 type API struct {
-	lister Lister
+	lister         Lister
+	clusters       *ClusterManager
+	graphSnapshots *GraphSnapshotStore
+	options        []Option
 }
 
 // NewAPI creates an instance of API.
-func NewAPI(lister Lister) *API {
+func NewAPI(lister Lister, options ...Option) *API {
 	a := &API{
-		lister: lister,
+		lister:         lister,
+		graphSnapshots: NewGraphSnapshotStore(DefaultGraphSnapshotLimit),
+		options:        options,
+	}
+	return a
+}
+
+// NewMultiClusterAPI creates an instance of API for managed clusters. Routes without a cluster
+// use the default cluster.
+func NewMultiClusterAPI(clusters *ClusterManager, options ...Option) *API {
+	a := &API{
+		lister:         &clusterLister{clusters: clusters, name: clusters.DefaultCluster()},
+		clusters:       clusters,
+		graphSnapshots: NewGraphSnapshotStore(DefaultGraphSnapshotLimit),
+		options:        options,
 	}
 	return a
 }
@@ -41,8 +58,26 @@ func (a *API) Handler(ctx context.Context) *mux.Router {
 	r.Use(logMiddleware(logger))
 	r.Use(configureCORS)
 
-	r.Handle("/v1/nodes", NewNodeHandler(a.lister)).Methods(http.MethodGet)
-	r.Handle("/v1/ws", NewWebsocketHandler(a.lister))
+	r.Handle("/v1/nodes", NewNodeHandler(a.lister, a.options...)).Methods(http.MethodGet)
+	r.Handle("/v1/subgraph", NewSubgraphHandler(a.lister, a.options...)).Methods(http.MethodGet)
+	r.Handle("/v1/impact", NewImpactHandler(a.lister, a.options...)).Methods(http.MethodGet)
+	r.Handle("/v1/paths", NewPathHandler(a.lister, a.options...)).Methods(http.MethodGet)
+	r.Handle("/v1/orphans", NewOrphanHandler(a.lister, a.options...)).Methods(http.MethodGet)
+	r.Handle("/v1/namespaces/{namespace}/graph.svg", NewSVGHandler(a.lister, a.options...)).Methods(http.MethodGet)
+	r.Handle("/v1/skipped-resources", NewSkippedResourceHandler(a.lister, a.clusters)).Methods(http.MethodGet)
+	r.Handle("/v1/snapshots", NewGraphSnapshotHandler(a.lister, a.graphSnapshots, a.options...)).
+		Methods(http.MethodGet, http.MethodPost)
+	r.Handle("/v1/snapshots/{id}/diff", NewGraphDiffHandler(a.lister, a.graphSnapshots, a.options...)).
+		Methods(http.MethodGet)
+	r.Handle("/v1/ws", NewWebsocketHandler(a.lister, a.clusters, a.options...))
+
+	if a.clusters != nil {
+		r.Handle("/v1/clusters", NewClusterHandler(a.clusters)).Methods(http.MethodGet)
+		r.Handle("/v1/clusters/{cluster}/namespaces/{namespace}/nodes", NewClusterNodeHandler(a.clusters, a.options...)).
+			Methods(http.MethodGet)
+		r.Handle("/v1/clusters/{cluster}/skipped-resources", NewSkippedResourceHandler(a.lister, a.clusters)).
+			Methods(http.MethodGet)
+	}
 
 	return r
 }
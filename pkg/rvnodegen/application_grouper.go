@@ -0,0 +1,123 @@
+package rvnodegen
+
+import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/utils/pointer"
+)
+
+// ApplicationLabelKeys are the label keys used to group objects into applications.
+type ApplicationLabelKeys struct {
+	// PartOf is the key for the higher level application an object is part of.
+	PartOf string
+	// Instance is the key for the application instance.
+	Instance string
+	// Name is the key for the application name. It is used when an object has no instance label.
+	Name string
+	// Component is the key for the component within an application.
+	Component string
+}
+
+// DefaultApplicationLabelKeys returns the Kubernetes recommended application label keys.
+func DefaultApplicationLabelKeys() ApplicationLabelKeys {
+	return ApplicationLabelKeys{
+		PartOf:    "app.kubernetes.io/part-of",
+		Instance:  "app.kubernetes.io/instance",
+		Name:      "app.kubernetes.io/name",
+		Component: "app.kubernetes.io/component",
+	}
+}
+
+// ApplicationGrouper groups objects into synthetic application nodes using labels. Groups are
+// nested part-of -> instance -> component.
+type ApplicationGrouper struct {
+	keys ApplicationLabelKeys
+}
+
+var _ Grouper = &ApplicationGrouper{}
+
+// NewApplicationGrouper creates an instance of ApplicationGrouper.
+func NewApplicationGrouper(keys ApplicationLabelKeys) *ApplicationGrouper {
+	a := &ApplicationGrouper{
+		keys: keys,
+	}
+	return a
+}
+
+// Name is the name of the grouper.
+func (a *ApplicationGrouper) Name() string {
+	return "Application"
+}
+
+// Group places ungrouped nodes into application groups.
+func (a *ApplicationGrouper) Group(nodes []GraphNode, objects map[string]*unstructured.Unstructured) ([]GraphNode, error) {
+	groups := map[string]*GraphNode{}
+	var groupIDs []string
+
+	for i := range nodes {
+		object, ok := groupableObject(nodes[i], objects)
+		if !ok {
+			continue
+		}
+
+		levels := a.levels(object.GetLabels())
+		if len(levels) == 0 {
+			continue
+		}
+
+		var parent *string
+		var path []string
+
+		for _, level := range levels {
+			path = append(path, level)
+			id := applicationGroupID(object.GetNamespace(), path)
+
+			group, ok := groups[id]
+			if !ok {
+				group = &GraphNode{
+					ID:           id,
+					Label:        level,
+					IsGroup:      pointer.StringPtr("yes"),
+					NodeType:     NodeTypeApplication,
+					HealthStatus: HealthStatusTypeHealthy,
+					Parent:       parent,
+				}
+				groups[id] = group
+				groupIDs = append(groupIDs, id)
+			}
+
+			group.HealthStatus = worseHealthStatus(group.HealthStatus, nodes[i].HealthStatus)
+			parent = pointer.StringPtr(id)
+		}
+
+		nodes[i].Parent = parent
+	}
+
+	for _, id := range groupIDs {
+		nodes = append(nodes, *groups[id])
+	}
+
+	return nodes, nil
+}
+
+// levels returns the non empty group levels for a set of labels.
+func (a *ApplicationGrouper) levels(objectLabels map[string]string) []string {
+	instance := objectLabels[a.keys.Instance]
+	if instance == "" {
+		instance = objectLabels[a.keys.Name]
+	}
+
+	var levels []string
+	for _, value := range []string{objectLabels[a.keys.PartOf], instance, objectLabels[a.keys.Component]} {
+		if value != "" {
+			levels = append(levels, value)
+		}
+	}
+
+	return levels
+}
+
+func applicationGroupID(namespace string, path []string) string {
+	return "application:" + namespace + ":" + strings.Join(path, "/")
+}
@@ -0,0 +1,178 @@
+package rvnodegen
+
+import (
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const argoCDTrackingIDAnnotation = "argocd.argoproj.io/tracking-id"
+
+// ArgoCDVisitor visits objects managed by Argo CD applications. Objects are placed in a group
+// for the Application that manages them. Applications are found using the tracking id
+// annotation, or the instance label if one is set with ArgoCDInstanceLabel.
+type ArgoCDVisitor struct {
+	lister       Lister
+	applications []*unstructured.Unstructured
+	listed       bool
+}
+
+var _ ResourceVisitor = &ArgoCDVisitor{}
+
+// NewArgoCDVisitor creates an instance of ArgoCDVisitor.
+func NewArgoCDVisitor(lister Lister) *ArgoCDVisitor {
+	a := &ArgoCDVisitor{
+		lister: lister,
+	}
+	return a
+}
+
+// Name is the name of the resource visitor.
+func (a *ArgoCDVisitor) Name() string {
+	return "ArgoCD"
+}
+
+// Matches returns a group/version/kind that this resource visitor matches. Any object can
+// be managed by Argo CD.
+func (a *ArgoCDVisitor) Matches(gvk schema.GroupVersionKind) bool {
+	return true
+}
+
+// Visit visits an object that could be managed by Argo CD.
+func (a *ArgoCDVisitor) Visit(object *unstructured.Unstructured, node GraphNode, visitor *Visitor) (GraphNode, error) {
+	if object.GroupVersionKind().String() == argoApplicationGVK.String() {
+		var err error
+		node, err = argoCDApplicationNode(object, node)
+		if err != nil {
+			return GraphNode{}, err
+		}
+	}
+
+	application, err := a.trackingApplication(object, visitor.argoCDInstanceLabel)
+	if err != nil {
+		return GraphNode{}, err
+	}
+
+	if application == nil {
+		return node, nil
+	}
+
+	return setGitOpsParent(application, node, visitor)
+}
+
+// trackingApplication returns the application tracking an object, or nil if there isn't one.
+func (a *ArgoCDVisitor) trackingApplication(object *unstructured.Unstructured, instanceLabel string) (*unstructured.Unstructured, error) {
+	name, namespace := argoCDTrackedApplication(object, instanceLabel)
+	if name == "" {
+		return nil, nil
+	}
+
+	application, err := a.findApplication(name, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	if application == nil || application.GetUID() == object.GetUID() {
+		return nil, nil
+	}
+
+	return application, nil
+}
+
+func (a *ArgoCDVisitor) findApplication(name, namespace string) (*unstructured.Unstructured, error) {
+	if !a.listed {
+		applications, err := a.lister.List(argoApplicationGVK, labels.Everything())
+		if err != nil && !isUnavailable(err) {
+			return nil, fmt.Errorf("list argo cd applications: %w", err)
+		}
+
+		a.applications = applications
+		a.listed = true
+	}
+
+	for _, application := range a.applications {
+		if application.GetName() != name {
+			continue
+		}
+
+		if namespace != "" && application.GetNamespace() != namespace {
+			continue
+		}
+
+		return application, nil
+	}
+
+	return nil, nil
+}
+
+// argoCDTrackedApplication returns the name and optional namespace of the application tracking
+// an object. The tracking id has the format <application>:<group>/<kind>:<namespace>/<name>.
+// Applications outside of the control plane namespace are named <namespace>_<application>.
+// Objects without a tracking id are matched with the instance label if one is set.
+func argoCDTrackedApplication(object *unstructured.Unstructured, instanceLabel string) (string, string) {
+	if trackingID := object.GetAnnotations()[argoCDTrackingIDAnnotation]; trackingID != "" {
+		parts := strings.SplitN(trackingID, ":", 2)
+		application := parts[0]
+
+		if i := strings.Index(application, "_"); i > 0 {
+			return application[i+1:], application[:i]
+		}
+
+		return application, ""
+	}
+
+	// objects created by a controller inherit labels from their templates, so the instance
+	// label is only trusted on objects without a controller.
+	if instanceLabel == "" || metav1.GetControllerOf(object) != nil {
+		return "", ""
+	}
+
+	return object.GetLabels()[instanceLabel], ""
+}
+
+func argoCDApplicationNode(object *unstructured.Unstructured, node GraphNode) (GraphNode, error) {
+	health, _, err := unstructured.NestedString(object.Object, "status", "health", "status")
+	if err != nil {
+		return GraphNode{}, fmt.Errorf("get application health: %w", err)
+	}
+
+	syncStatus, _, err := unstructured.NestedString(object.Object, "status", "sync", "status")
+	if err != nil {
+		return GraphNode{}, fmt.Errorf("get application sync status: %w", err)
+	}
+
+	revision, _, err := unstructured.NestedString(object.Object, "status", "sync", "revision")
+	if err != nil {
+		return GraphNode{}, fmt.Errorf("get application sync revision: %w", err)
+	}
+
+	if syncStatus == "" {
+		syncStatus = SyncStatusUnknown
+	}
+
+	switch health {
+	case "Healthy":
+		node.HealthStatus = HealthStatusTypeHealthy
+	case "Degraded":
+		node.HealthStatus = HealthStatusTypeFailure
+	default:
+		// Progressing, Suspended, Missing and Unknown
+		node.HealthStatus = HealthStatusTypeDegraded
+	}
+
+	node.NodeType = NodeTypeGitOps
+
+	if node.Extra == nil {
+		node.Extra = map[string]interface{}{}
+	}
+	node.Extra["controller"] = "argocd"
+	node.Extra["health"] = health
+	node.Extra["syncStatus"] = syncStatus
+	node.Extra["revision"] = revision
+
+	return node, nil
+}
@@ -0,0 +1,144 @@
+package rvnodegen
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var argoApplicationResource = fakeResource{gvk: argoApplicationGVK, resource: "applications", namespaced: true, custom: true}
+
+func newTestArgoApplication(namespace, name, health, syncStatus string) *unstructured.Unstructured {
+	application := newTestObject(argoApplicationGVK, namespace, name)
+	_ = unstructured.SetNestedField(application.Object, health, "status", "health", "status")
+	_ = unstructured.SetNestedField(application.Object, syncStatus, "status", "sync", "status")
+	_ = unstructured.SetNestedField(application.Object, "abc123", "status", "sync", "revision")
+	return application
+}
+
+func TestArgoCDVisitor_Visit(t *testing.T) {
+	tracked := newTestObject(podGVK, "default", "tracked")
+	tracked.SetAnnotations(map[string]string{argoCDTrackingIDAnnotation: "guestbook:/Pod:default/tracked"})
+
+	trackedOutsideControlPlane := newTestObject(podGVK, "default", "outside")
+	trackedOutsideControlPlane.SetAnnotations(map[string]string{
+		argoCDTrackingIDAnnotation: "apps_podinfo:/Pod:default/outside",
+	})
+
+	labeled := newTestObject(podGVK, "default", "labeled")
+	labeled.SetLabels(map[string]string{"app.kubernetes.io/instance": "guestbook"})
+
+	untracked := newTestObject(podGVK, "default", "untracked")
+
+	argocdApplication := newTestArgoApplication("argocd", "guestbook", "Healthy", "Synced")
+	appsApplication := newTestArgoApplication("apps", "podinfo", "Degraded", "OutOfSync")
+
+	tests := []struct {
+		name       string
+		object     *unstructured.Unstructured
+		options    []Option
+		wantParent string
+	}{
+		{
+			name:       "tracking id",
+			object:     tracked,
+			wantParent: string(argocdApplication.GetUID()),
+		},
+		{
+			name:       "tracking id with application namespace",
+			object:     trackedOutsideControlPlane,
+			wantParent: string(appsApplication.GetUID()),
+		},
+		{
+			name:   "instance label is ignored by default",
+			object: labeled,
+		},
+		{
+			name:       "instance label",
+			object:     labeled,
+			options:    []Option{ArgoCDInstanceLabel("app.kubernetes.io/instance")},
+			wantParent: string(argocdApplication.GetUID()),
+		},
+		{
+			name:   "untracked",
+			object: untracked,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			lister := newFakeClusterLister(t, []fakeResource{argoApplicationResource},
+				test.object, argocdApplication, appsApplication)
+
+			nodes := visitWithResourceVisitor(t, lister, NewArgoCDVisitor(lister),
+				[]*unstructured.Unstructured{test.object}, test.options...)
+
+			node, ok := nodes[string(test.object.GetUID())]
+			if !ok {
+				t.Fatalf("node for %s was not emitted", test.object.GetName())
+			}
+
+			if got := nodeParent(node); got != test.wantParent {
+				t.Errorf("parent = %q, want %q", got, test.wantParent)
+			}
+
+			if test.wantParent == "" {
+				return
+			}
+
+			if _, ok := nodes[test.wantParent]; !ok {
+				t.Errorf("application %s was not emitted", test.wantParent)
+			}
+		})
+	}
+}
+
+func TestArgoCDVisitor_Visit_application(t *testing.T) {
+	tests := []struct {
+		name           string
+		health         string
+		syncStatus     string
+		wantHealth     HealthStatusType
+		wantSyncStatus string
+	}{
+		{name: "healthy", health: "Healthy", syncStatus: "Synced", wantHealth: HealthStatusTypeHealthy, wantSyncStatus: "Synced"},
+		{name: "degraded", health: "Degraded", syncStatus: "OutOfSync", wantHealth: HealthStatusTypeFailure, wantSyncStatus: "OutOfSync"},
+		{name: "progressing", health: "Progressing", syncStatus: "Synced", wantHealth: HealthStatusTypeDegraded, wantSyncStatus: "Synced"},
+		{name: "no sync status", health: "Healthy", wantHealth: HealthStatusTypeHealthy, wantSyncStatus: SyncStatusUnknown},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			application := newTestArgoApplication("argocd", "guestbook", test.health, test.syncStatus)
+			if test.syncStatus == "" {
+				unstructured.RemoveNestedField(application.Object, "status", "sync", "status")
+			}
+
+			lister := newFakeClusterLister(t, []fakeResource{argoApplicationResource}, application)
+
+			nodes := visitWithResourceVisitor(t, lister, NewArgoCDVisitor(lister),
+				[]*unstructured.Unstructured{application})
+
+			node, ok := nodes[string(application.GetUID())]
+			if !ok {
+				t.Fatalf("application node was not emitted")
+			}
+
+			if node.NodeType != NodeTypeGitOps {
+				t.Errorf("node type = %q, want %q", node.NodeType, NodeTypeGitOps)
+			}
+
+			if node.HealthStatus != test.wantHealth {
+				t.Errorf("health status = %q, want %q", node.HealthStatus, test.wantHealth)
+			}
+
+			if got := node.Extra["syncStatus"]; got != test.wantSyncStatus {
+				t.Errorf("sync status = %v, want %q", got, test.wantSyncStatus)
+			}
+
+			if got := node.Extra["revision"]; got != "abc123" {
+				t.Errorf("revision = %v, want %q", got, "abc123")
+			}
+		})
+	}
+}
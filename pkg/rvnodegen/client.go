@@ -6,6 +6,7 @@ import (
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/discovery/cached/disk"
 	"k8s.io/client-go/dynamic"
+	authorizationv1client "k8s.io/client-go/kubernetes/typed/authorization/v1"
 	restclient "k8s.io/client-go/rest"
 
 	// import all the Kubernetes auth packages.
@@ -16,6 +17,8 @@ import (
 type Client struct {
 	discoveryClient discovery.DiscoveryInterface
 	dynamicClient   dynamic.Interface
+
+	authorizationClient authorizationv1client.AuthorizationV1Interface
 }
 
 // NewClient creates an instance of Client.
@@ -36,9 +39,15 @@ func NewClient(restConfig *restclient.Config, options ...Option) (*Client, error
 		return nil, fmt.Errorf("create dynamic client: %w", err)
 	}
 
+	authorizationClient, err := authorizationv1client.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("create authorization client: %w", err)
+	}
+
 	c := &Client{
-		discoveryClient: discoveryClient,
-		dynamicClient:   dynamicClient,
+		discoveryClient:     discoveryClient,
+		dynamicClient:       dynamicClient,
+		authorizationClient: authorizationClient,
 	}
 
 	return c, nil
@@ -53,3 +62,8 @@ func (c *Client) DiscoveryClient() discovery.DiscoveryInterface {
 func (c *Client) DynamicClient() dynamic.Interface {
 	return c.dynamicClient
 }
+
+// AuthorizationClient returns an authorization client.
+func (c *Client) AuthorizationClient() authorizationv1client.AuthorizationV1Interface {
+	return c.authorizationClient
+}
@@ -0,0 +1,83 @@
+package rvnodegen
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/gorilla/mux"
+)
+
+type clustersResponse struct {
+	Clusters []ClusterStatus `json:"clusters"`
+}
+
+// ClusterHandler is a HTTP handler that lists managed clusters with their connection and sync
+// status.
+type ClusterHandler struct {
+	clusters *ClusterManager
+}
+
+var _ http.Handler = &ClusterHandler{}
+
+// NewClusterHandler creates an instance of ClusterHandler.
+func NewClusterHandler(clusters *ClusterManager) *ClusterHandler {
+	h := &ClusterHandler{
+		clusters: clusters,
+	}
+	return h
+}
+
+// ServeHTTP serves the handler.
+func (h *ClusterHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	respondWithJSON(w, clustersResponse{Clusters: h.clusters.Clusters()}, http.StatusOK)
+}
+
+// ClusterNodeHandler is a HTTP handler for generating the nodes in a cluster's namespace. The
+// cluster and namespace are path variables, and the query parameters are the same as the node
+// handler's.
+type ClusterNodeHandler struct {
+	clusters *ClusterManager
+	options  []Option
+
+	mu       sync.Mutex
+	handlers map[string]*NodeHandler
+}
+
+var _ http.Handler = &ClusterNodeHandler{}
+
+// NewClusterNodeHandler creates an instance of ClusterNodeHandler.
+func NewClusterNodeHandler(clusters *ClusterManager, options ...Option) *ClusterNodeHandler {
+	h := &ClusterNodeHandler{
+		clusters: clusters,
+		options:  options,
+		handlers: map[string]*NodeHandler{},
+	}
+	return h
+}
+
+// ServeHTTP serves the handler.
+func (h *ClusterNodeHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	lister, err := h.clusters.Lister(vars["cluster"])
+	if err != nil {
+		respondWithError(w, err, clusterErrorStatus(err))
+		return
+	}
+
+	h.nodeHandler(vars["cluster"], lister).serveScope(w, r, Scope{Namespaces: []string{vars["namespace"]}})
+}
+
+// nodeHandler returns the node handler for a cluster, so each cluster keeps its own layouts.
+func (h *ClusterNodeHandler) nodeHandler(cluster string, lister Lister) *NodeHandler {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	handler, ok := h.handlers[cluster]
+	if !ok {
+		handler = NewNodeHandler(lister, h.options...)
+		h.handlers[cluster] = handler
+	}
+
+	return handler
+}
@@ -0,0 +1,335 @@
+package rvnodegen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/bryanl/rv-node-gen/internal/log"
+)
+
+// clusterRetryInterval is how long the cluster manager waits before reconnecting to a cluster
+// that failed to connect.
+const clusterRetryInterval = 30 * time.Second
+
+var (
+	// ErrClusterNotFound is returned when a cluster is not managed by the cluster manager.
+	ErrClusterNotFound = errors.New("cluster not found")
+	// ErrClusterNotReady is returned when a cluster's informers have not synced.
+	ErrClusterNotReady = errors.New("cluster not ready")
+)
+
+// ClusterState is the connection state of a cluster.
+type ClusterState string
+
+const (
+	// ClusterStateConnecting is a cluster that is connecting or syncing its informers.
+	ClusterStateConnecting ClusterState = "Connecting"
+	// ClusterStateReady is a cluster with synced informers.
+	ClusterStateReady ClusterState = "Ready"
+	// ClusterStateFailed is a cluster that failed to connect. It is retried.
+	ClusterStateFailed ClusterState = "Failed"
+)
+
+// ClusterStatus is the status of a managed cluster.
+type ClusterStatus struct {
+	// Name is the kube config context for the cluster.
+	Name string `json:"name"`
+	// Server is the cluster's API server.
+	Server string `json:"server,omitempty"`
+	// Default is true for the cluster served by the API's cluster-less routes.
+	Default bool `json:"default,omitempty"`
+	// State is the connection state.
+	State ClusterState `json:"state"`
+	// Synced is true once the cluster's informers have synced.
+	Synced bool `json:"synced"`
+	// SyncedAt is when the cluster's informers synced.
+	SyncedAt *time.Time `json:"syncedAt,omitempty"`
+	// Error is the last connection error.
+	Error string `json:"error,omitempty"`
+}
+
+// ClusterManager manages an informer manager for each cluster in a kube config. Clusters are
+// keyed by context, and connect independently, so a cluster that fails to connect does not
+// block the others.
+type ClusterManager struct {
+	kubeConfig     KubeConfig
+	defaultCluster string
+
+	mu       sync.RWMutex
+	clusters map[string]*managedCluster
+}
+
+type managedCluster struct {
+	status ClusterStatus
+	lister Lister
+}
+
+// NewClusterManager creates an instance of ClusterManager for contexts in a kube config. If no
+// contexts are supplied, all contexts are managed. The selected context is the default cluster
+// if it is managed, otherwise the first context is. The kube config's cluster, user and
+// namespace overrides apply to every context.
+func NewClusterManager(kubeConfig KubeConfig, contexts ...string) (*ClusterManager, error) {
+	config, err := kubeConfig.load()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(contexts) == 0 {
+		for name := range config.Contexts {
+			contexts = append(contexts, name)
+		}
+	}
+
+	if len(contexts) == 0 {
+		return nil, fmt.Errorf("kube config does not have contexts")
+	}
+
+	sort.Strings(contexts)
+
+	currentContext := config.CurrentContext
+	if kubeConfig.Context != "" {
+		currentContext = kubeConfig.Context
+	}
+
+	cm := &ClusterManager{
+		kubeConfig:     kubeConfig,
+		defaultCluster: contexts[0],
+		clusters:       map[string]*managedCluster{},
+	}
+
+	for _, name := range contexts {
+		kubeContext, ok := config.Contexts[name]
+		if !ok {
+			return nil, fmt.Errorf("context %q: %w", name, ErrClusterNotFound)
+		}
+
+		status := ClusterStatus{
+			Name:  name,
+			State: ClusterStateConnecting,
+		}
+		if cluster, ok := config.Clusters[kubeContext.Cluster]; ok {
+			status.Server = cluster.Server
+		}
+
+		if name == currentContext {
+			cm.defaultCluster = name
+		}
+
+		cm.clusters[name] = &managedCluster{status: status}
+	}
+
+	cm.clusters[cm.defaultCluster].status.Default = true
+
+	return cm, nil
+}
+
+// Start connects to the clusters in the background. Clusters that fail to connect are retried
+// until the context is done, and connected clusters' informers are stopped when it is.
+func (cm *ClusterManager) Start(ctx context.Context) {
+	for name := range cm.clusters {
+		go cm.connect(ctx, name)
+	}
+}
+
+func (cm *ClusterManager) connect(ctx context.Context, name string) {
+	logger := log.From(ctx).WithValues("cluster", name)
+
+	for {
+		logger.Info("Connecting to cluster")
+
+		informerManager, err := NewClusterInformerManager(cm.kubeConfig.forContext(name), Logger(logger))
+		if err == nil {
+			now := time.Now()
+			cm.update(name, func(cluster *managedCluster) {
+				cluster.lister = informerManager.Lister()
+				cluster.status.State = ClusterStateReady
+				cluster.status.Synced = true
+				cluster.status.SyncedAt = &now
+				cluster.status.Error = ""
+			})
+			logger.Info("Cluster synced")
+
+			<-ctx.Done()
+			informerManager.Stop()
+			return
+		}
+
+		logger.Error(err, "Connect to cluster")
+		cm.update(name, func(cluster *managedCluster) {
+			cluster.status.State = ClusterStateFailed
+			cluster.status.Error = err.Error()
+		})
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(clusterRetryInterval):
+		}
+
+		cm.update(name, func(cluster *managedCluster) {
+			cluster.status.State = ClusterStateConnecting
+		})
+	}
+}
+
+func (cm *ClusterManager) update(name string, fn func(cluster *managedCluster)) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	fn(cm.clusters[name])
+}
+
+// Clusters returns the status of the managed clusters sorted by name.
+func (cm *ClusterManager) Clusters() []ClusterStatus {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	var out []ClusterStatus
+	for _, cluster := range cm.clusters {
+		out = append(out, cluster.status)
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Name < out[j].Name
+	})
+
+	return out
+}
+
+// DefaultCluster returns the name of the default cluster.
+func (cm *ClusterManager) DefaultCluster() string {
+	return cm.defaultCluster
+}
+
+// Lister returns the lister for a cluster. It returns ErrClusterNotFound if the cluster is not
+// managed and ErrClusterNotReady if its informers have not synced.
+func (cm *ClusterManager) Lister(name string) (Lister, error) {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	cluster, ok := cm.clusters[name]
+	if !ok {
+		return nil, fmt.Errorf("%q: %w", name, ErrClusterNotFound)
+	}
+
+	if cluster.lister == nil {
+		return nil, fmt.Errorf("%q: %w", name, ErrClusterNotReady)
+	}
+
+	return cluster.lister, nil
+}
+
+// clusterLister is a lister for a managed cluster that resolves the cluster's lister on each
+// call, so it can be created before the cluster is ready.
+type clusterLister struct {
+	clusters *ClusterManager
+	name     string
+}
+
+var _ Lister = &clusterLister{}
+var _ SkippedResourceReporter = &clusterLister{}
+var _ ScopeResolver = &clusterLister{}
+var _ KindLister = &clusterLister{}
+
+func (l *clusterLister) List(gvk schema.GroupVersionKind, selector labels.Selector) ([]*unstructured.Unstructured, error) {
+	lister, err := l.clusters.Lister(l.name)
+	if err != nil {
+		return nil, err
+	}
+	return lister.List(gvk, selector)
+}
+
+func (l *clusterLister) Get(gvk schema.GroupVersionKind, name string) (*unstructured.Unstructured, error) {
+	lister, err := l.clusters.Lister(l.name)
+	if err != nil {
+		return nil, err
+	}
+	return lister.Get(gvk, name)
+}
+
+func (l *clusterLister) SkippedResources() ([]SkippedResource, error) {
+	lister, err := l.clusters.Lister(l.name)
+	if err != nil {
+		return nil, err
+	}
+
+	reporter, ok := lister.(SkippedResourceReporter)
+	if !ok {
+		return nil, nil
+	}
+	return reporter.SkippedResources()
+}
+
+func (l *clusterLister) IsNamespaced(gvk schema.GroupVersionKind) (bool, error) {
+	lister, err := l.clusters.Lister(l.name)
+	if err != nil {
+		return false, err
+	}
+
+	resolver, ok := lister.(ScopeResolver)
+	if !ok {
+		return false, fmt.Errorf("%s: %w", gvk, ErrUnknownResource)
+	}
+	return resolver.IsNamespaced(gvk)
+}
+
+func (l *clusterLister) Kinds() ([]schema.GroupVersionKind, error) {
+	lister, err := l.clusters.Lister(l.name)
+	if err != nil {
+		return nil, err
+	}
+
+	kindLister, ok := lister.(KindLister)
+	if !ok {
+		return nil, nil
+	}
+	return kindLister.Kinds()
+}
+
+func (l *clusterLister) ByNamespace(namespace string) NamespaceLister {
+	return &clusterNamespaceLister{clusterLister: l, namespace: namespace}
+}
+
+type clusterNamespaceLister struct {
+	clusterLister *clusterLister
+	namespace     string
+}
+
+var _ NamespaceLister = &clusterNamespaceLister{}
+
+func (n *clusterNamespaceLister) List(gvk schema.GroupVersionKind, selector labels.Selector) ([]*unstructured.Unstructured, error) {
+	lister, err := n.clusterLister.clusters.Lister(n.clusterLister.name)
+	if err != nil {
+		return nil, err
+	}
+	return lister.ByNamespace(n.namespace).List(gvk, selector)
+}
+
+func (n *clusterNamespaceLister) Get(gvk schema.GroupVersionKind, name string) (*unstructured.Unstructured, error) {
+	lister, err := n.clusterLister.clusters.Lister(n.clusterLister.name)
+	if err != nil {
+		return nil, err
+	}
+	return lister.ByNamespace(n.namespace).Get(gvk, name)
+}
+
+// clusterErrorStatus is the HTTP status for a cluster lookup error.
+func clusterErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrClusterNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrClusterNotReady):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
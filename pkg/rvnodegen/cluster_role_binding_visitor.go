@@ -0,0 +1,87 @@
+package rvnodegen
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ClusterRoleBindingVisitor visits a cluster role binding resource.
+type ClusterRoleBindingVisitor struct {
+	lister Lister
+}
+
+var _ ResourceVisitor = &ClusterRoleBindingVisitor{}
+
+// NewClusterRoleBindingVisitor creates an instance of ClusterRoleBindingVisitor.
+func NewClusterRoleBindingVisitor(lister Lister) *ClusterRoleBindingVisitor {
+	c := &ClusterRoleBindingVisitor{
+		lister: lister,
+	}
+	return c
+}
+
+// Name is the name of the resource visitor.
+func (c *ClusterRoleBindingVisitor) Name() string {
+	return "ClusterRoleBinding"
+}
+
+// Matches returns a group/version/kind that this resource visitor matches.
+func (c *ClusterRoleBindingVisitor) Matches(gvk schema.GroupVersionKind) bool {
+	return clusterRoleBindingGVK.String() == gvk.String()
+}
+
+// Visit visits a cluster role binding resource. It targets the bound cluster role and the
+// service accounts it is bound to in the visitor's namespaces.
+func (c *ClusterRoleBindingVisitor) Visit(object *unstructured.Unstructured, node GraphNode, visitor *Visitor) (GraphNode, error) {
+	references, err := clusterRoleBindingReferences(object)
+	if err != nil {
+		return GraphNode{}, err
+	}
+
+	return visitEdgeReferences(visitor, c.Name(), object, references, node)
+}
+
+// clusterRoleBindingReferences returns the references a cluster role binding makes to the
+// cluster role it binds and its service account subjects.
+func clusterRoleBindingReferences(object *unstructured.Unstructured) ([]edgeReference, error) {
+	var references []edgeReference
+
+	roleName, _, err := unstructured.NestedString(object.Object, "roleRef", "name")
+	if err != nil {
+		return nil, err
+	}
+
+	if roleName != "" {
+		references = append(references, edgeReference{gvk: clusterRoleGVK, name: roleName,
+			relation: EdgeRelationRBAC, attributes: map[string]string{"field": "roleRef"},
+			field: "roleRef", verb: "grants"})
+	}
+
+	subjects, _, err := unstructured.NestedSlice(object.Object, "subjects")
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range subjects {
+		subject, ok := subjects[i].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		if kind, _, _ := unstructured.NestedString(subject, "kind"); kind != serviceAccountGVK.Kind {
+			continue
+		}
+
+		namespace, _, _ := unstructured.NestedString(subject, "namespace")
+		name, _, _ := unstructured.NestedString(subject, "name")
+		if name == "" {
+			continue
+		}
+
+		references = append(references, edgeReference{gvk: serviceAccountGVK, namespace: namespace, name: name,
+			relation: EdgeRelationRBAC, attributes: map[string]string{"field": "subjects"},
+			field: "subjects", verb: "binds"})
+	}
+
+	return references, nil
+}
@@ -0,0 +1,411 @@
+package rvnodegen
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/go-logr/logr"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	clusterSnapshotVersion   = 1
+	clusterSnapshotIndexName = "snapshot.json"
+
+	lastAppliedConfigurationAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+)
+
+// clusterSnapshotIndex is the index of a cluster snapshot archive.
+type clusterSnapshotIndex struct {
+	Version    int                       `json:"version"`
+	CapturedAt time.Time                 `json:"capturedAt"`
+	Resources  []clusterSnapshotResource `json:"resources"`
+}
+
+// clusterSnapshotResource is a discovered resource and the archive file with its objects.
+type clusterSnapshotResource struct {
+	Group      string `json:"group,omitempty"`
+	Version    string `json:"version"`
+	Kind       string `json:"kind"`
+	Resource   string `json:"resource"`
+	Namespaced bool   `json:"namespaced"`
+	File       string `json:"file"`
+}
+
+func (r clusterSnapshotResource) gvk() schema.GroupVersionKind {
+	return schema.GroupVersionKind{Group: r.Group, Version: r.Version, Kind: r.Kind}
+}
+
+func (r clusterSnapshotResource) gvr() schema.GroupVersionResource {
+	return schema.GroupVersionResource{Group: r.Group, Version: r.Version, Resource: r.Resource}
+}
+
+// WriteSnapshot writes the objects in the informer caches to w as a tar archive. The archive has
+// an index with the discovered resources, and a list of objects for each resource. Secret data
+// is redacted, except for the Helm release fields node gen reads.
+func (im *InformerManager) WriteSnapshot(w io.Writer) error {
+	var gvks []schema.GroupVersionKind
+	for gvk := range im.mapping {
+		gvks = append(gvks, gvk)
+	}
+	sort.Slice(gvks, func(i, j int) bool {
+		return gvks[i].String() < gvks[j].String()
+	})
+
+	tw := tar.NewWriter(w)
+	now := time.Now()
+
+	index := clusterSnapshotIndex{
+		Version:    clusterSnapshotVersion,
+		CapturedAt: now.UTC(),
+	}
+
+	for _, gvk := range gvks {
+		resource := im.mapping[gvk]
+
+		objects, err := newLister(im).List(gvk, labels.Everything())
+		if err != nil {
+			return fmt.Errorf("list %s: %w", gvk, err)
+		}
+
+		list := &unstructured.UnstructuredList{Object: map[string]interface{}{}}
+		list.SetAPIVersion("v1")
+		list.SetKind("List")
+		for _, object := range objects {
+			object = object.DeepCopy()
+			object.SetGroupVersionKind(gvk)
+			if gvk == secretGVK {
+				if err := redactSecret(object, im.logger); err != nil {
+					return fmt.Errorf("redact secret %s/%s: %w", object.GetNamespace(), object.GetName(), err)
+				}
+			}
+			list.Items = append(list.Items, *object)
+		}
+
+		data, err := list.MarshalJSON()
+		if err != nil {
+			return fmt.Errorf("marshal %s: %w", gvk, err)
+		}
+
+		group := resource.Group
+		if group == "" {
+			group = "core"
+		}
+
+		entry := clusterSnapshotResource{
+			Group:      gvk.Group,
+			Version:    gvk.Version,
+			Kind:       gvk.Kind,
+			Resource:   resource.Resource,
+			Namespaced: im.namespaced[gvk],
+			File:       path.Join("resources", group, resource.Version, resource.Resource+".json"),
+		}
+		index.Resources = append(index.Resources, entry)
+
+		if err := writeTarFile(tw, entry.File, data, now); err != nil {
+			return err
+		}
+	}
+
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal snapshot index: %w", err)
+	}
+
+	if err := writeTarFile(tw, clusterSnapshotIndexName, data, now); err != nil {
+		return err
+	}
+
+	return tw.Close()
+}
+
+func writeTarFile(tw *tar.Writer, name string, data []byte, modTime time.Time) error {
+	header := &tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    int64(len(data)),
+		ModTime: modTime,
+	}
+
+	if err := tw.WriteHeader(header); err != nil {
+		return fmt.Errorf("write %s header: %w", name, err)
+	}
+
+	if _, err := tw.Write(data); err != nil {
+		return fmt.Errorf("write %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// redactSecret removes a secret's values. Keys are kept so references to them can be checked.
+// Helm release secrets keep the release fields node gen reads, with the rest of the release
+// (including values) removed. Release secrets that can't be decoded are logged with logger and
+// redacted like other secrets.
+func redactSecret(secret *unstructured.Unstructured, logger logr.Logger) error {
+	data, _, err := unstructured.NestedStringMap(secret.Object, "data")
+	if err != nil {
+		return err
+	}
+
+	secretType, _, _ := unstructured.NestedString(secret.Object, "type")
+
+	redacted := map[string]interface{}{}
+	for key := range data {
+		redacted[key] = ""
+	}
+
+	if secretType == helmReleaseSecretType {
+		release, err := redactHelmRelease(secret)
+		if err != nil {
+			logger.Error(err, "Redact undecodable helm release secret",
+				"namespace", secret.GetNamespace(), "name", secret.GetName())
+		} else {
+			redacted["release"] = release
+		}
+	}
+
+	if len(data) > 0 {
+		if err := unstructured.SetNestedMap(secret.Object, redacted, "data"); err != nil {
+			return err
+		}
+	}
+	unstructured.RemoveNestedField(secret.Object, "stringData")
+	unstructured.RemoveNestedField(secret.Object, "metadata", "managedFields")
+
+	annotations := secret.GetAnnotations()
+	if _, ok := annotations[lastAppliedConfigurationAnnotation]; ok {
+		delete(annotations, lastAppliedConfigurationAnnotation)
+		secret.SetAnnotations(annotations)
+	}
+
+	return nil
+}
+
+// redactHelmRelease returns the secret's release data with only the fields decodeHelmRelease
+// reads. It is encoded the way Helm encodes releases, without compression.
+func redactHelmRelease(secret *unstructured.Unstructured) (string, error) {
+	release, err := decodeHelmRelease(secret)
+	if err != nil {
+		return "", err
+	}
+
+	var rd helmReleaseData
+	rd.Name = release.Name
+	rd.Namespace = release.Namespace
+	rd.Version = release.Revision
+	rd.Info.Status = release.Status
+	rd.Chart.Metadata.Name = release.Chart
+	rd.Chart.Metadata.Version = release.ChartVersion
+	rd.Chart.Metadata.AppVersion = release.AppVersion
+
+	data, err := json.Marshal(rd)
+	if err != nil {
+		return "", fmt.Errorf("marshal release: %w", err)
+	}
+
+	encoded := base64.StdEncoding.EncodeToString(data)
+	return base64.StdEncoding.EncodeToString([]byte(encoded)), nil
+}
+
+// ClusterSnapshot is a cluster's objects captured by InformerManager.WriteSnapshot.
+type ClusterSnapshot struct {
+	// CapturedAt is when the snapshot was captured.
+	CapturedAt time.Time
+
+	mapping    map[schema.GroupVersionKind]schema.GroupVersionResource
+	namespaced map[schema.GroupVersionKind]bool
+	objects    map[schema.GroupVersionKind][]*unstructured.Unstructured
+}
+
+// ReadClusterSnapshot reads a cluster snapshot archive. The archive may be gzip compressed.
+func ReadClusterSnapshot(r io.Reader) (*ClusterSnapshot, error) {
+	br := bufio.NewReader(r)
+	if magic, err := br.Peek(len(gzipMagic)); err == nil && bytes.Equal(magic, gzipMagic) {
+		gr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("read compressed snapshot: %w", err)
+		}
+		r = gr
+	} else {
+		r = br
+	}
+
+	files := map[string][]byte{}
+
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read snapshot archive: %w", err)
+		}
+
+		data, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", header.Name, err)
+		}
+		files[header.Name] = data
+	}
+
+	data, ok := files[clusterSnapshotIndexName]
+	if !ok {
+		return nil, fmt.Errorf("snapshot archive does not contain %s", clusterSnapshotIndexName)
+	}
+
+	var index clusterSnapshotIndex
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("unmarshal snapshot index: %w", err)
+	}
+
+	if index.Version != clusterSnapshotVersion {
+		return nil, fmt.Errorf("unsupported snapshot version %d", index.Version)
+	}
+
+	s := &ClusterSnapshot{
+		CapturedAt: index.CapturedAt,
+		mapping:    map[schema.GroupVersionKind]schema.GroupVersionResource{},
+		namespaced: map[schema.GroupVersionKind]bool{},
+		objects:    map[schema.GroupVersionKind][]*unstructured.Unstructured{},
+	}
+
+	for _, resource := range index.Resources {
+		s.mapping[resource.gvk()] = resource.gvr()
+		s.namespaced[resource.gvk()] = resource.Namespaced
+
+		data, ok := files[resource.File]
+		if !ok {
+			return nil, fmt.Errorf("snapshot archive does not contain %s", resource.File)
+		}
+
+		list := &unstructured.UnstructuredList{}
+		if err := list.UnmarshalJSON(data); err != nil {
+			return nil, fmt.Errorf("unmarshal %s: %w", resource.File, err)
+		}
+
+		for i := range list.Items {
+			s.objects[resource.gvk()] = append(s.objects[resource.gvk()], &list.Items[i])
+		}
+	}
+
+	return s, nil
+}
+
+// ReadClusterSnapshotFile reads a cluster snapshot archive from a file.
+func ReadClusterSnapshotFile(name string) (*ClusterSnapshot, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ReadClusterSnapshot(f)
+}
+
+// Lister returns a lister for the snapshot's objects. Like a cluster lister, it only lists
+// resources the cluster served when the snapshot was captured.
+func (s *ClusterSnapshot) Lister() Lister {
+	return &snapshotLister{snapshot: s}
+}
+
+func (s *ClusterSnapshot) list(gvk schema.GroupVersionKind, namespace string, selector labels.Selector) ([]*unstructured.Unstructured, error) {
+	if _, ok := s.mapping[gvk]; !ok {
+		return nil, fmt.Errorf("%s: %w", gvk, ErrUnknownResource)
+	}
+
+	var out []*unstructured.Unstructured
+	for _, object := range s.objects[gvk] {
+		if namespace != "" && object.GetNamespace() != namespace {
+			continue
+		}
+		if selector.Matches(labels.Set(object.GetLabels())) {
+			out = append(out, object)
+		}
+	}
+
+	return out, nil
+}
+
+func (s *ClusterSnapshot) get(gvk schema.GroupVersionKind, namespace, name string) (*unstructured.Unstructured, error) {
+	resource, ok := s.mapping[gvk]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", gvk, ErrUnknownResource)
+	}
+
+	for _, object := range s.objects[gvk] {
+		if object.GetNamespace() == namespace && object.GetName() == name {
+			return object, nil
+		}
+	}
+
+	return nil, kerrors.NewNotFound(resource.GroupResource(), name)
+}
+
+type snapshotLister struct {
+	snapshot *ClusterSnapshot
+}
+
+var _ Lister = &snapshotLister{}
+var _ ScopeResolver = &snapshotLister{}
+var _ KindLister = &snapshotLister{}
+
+func (l *snapshotLister) List(gvk schema.GroupVersionKind, selector labels.Selector) ([]*unstructured.Unstructured, error) {
+	return l.snapshot.list(gvk, "", selector)
+}
+
+func (l *snapshotLister) Get(gvk schema.GroupVersionKind, name string) (*unstructured.Unstructured, error) {
+	return l.snapshot.get(gvk, "", name)
+}
+
+func (l *snapshotLister) IsNamespaced(gvk schema.GroupVersionKind) (bool, error) {
+	if _, ok := l.snapshot.mapping[gvk]; !ok {
+		return false, fmt.Errorf("%s: %w", gvk, ErrUnknownResource)
+	}
+	return l.snapshot.namespaced[gvk], nil
+}
+
+func (l *snapshotLister) Kinds() ([]schema.GroupVersionKind, error) {
+	kinds := map[schema.GroupVersionKind]bool{}
+	for gvk := range l.snapshot.mapping {
+		kinds[gvk] = true
+	}
+	return sortedKinds(kinds), nil
+}
+
+func (l *snapshotLister) ByNamespace(namespace string) NamespaceLister {
+	return &snapshotNamespaceLister{
+		snapshot:  l.snapshot,
+		namespace: namespace,
+	}
+}
+
+type snapshotNamespaceLister struct {
+	snapshot  *ClusterSnapshot
+	namespace string
+}
+
+var _ NamespaceLister = &snapshotNamespaceLister{}
+
+func (n *snapshotNamespaceLister) List(gvk schema.GroupVersionKind, selector labels.Selector) ([]*unstructured.Unstructured, error) {
+	return n.snapshot.list(gvk, n.namespace, selector)
+}
+
+func (n *snapshotNamespaceLister) Get(gvk schema.GroupVersionKind, name string) (*unstructured.Unstructured, error) {
+	return n.snapshot.get(gvk, n.namespace, name)
+}
@@ -3,6 +3,7 @@ package rvnodegen
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"time"
 )
 
@@ -21,23 +22,28 @@ type CommandHandler interface {
 }
 
 // CommandsFactory is a factory for generating a list of command handlers.
-func CommandsFactory(lister Lister) []CommandHandler {
+func CommandsFactory(lister Lister, clusters *ClusterManager, options ...Option) []CommandHandler {
 	return []CommandHandler{
-		NewWorkloadsCommand(lister),
+		NewWorkloadsCommand(lister, clusters, options...),
 	}
 }
 
 // WorkloadsCommand is a workloads command.
 type WorkloadsCommand struct {
-	lister Lister
+	lister   Lister
+	clusters *ClusterManager
+	options  []Option
 }
 
 var _ CommandHandler = &WorkloadsCommand{}
 
-// NewWorkloadsCommand creates an instance of WorkloadsCommand.
-func NewWorkloadsCommand(lister Lister) *WorkloadsCommand {
+// NewWorkloadsCommand creates an instance of WorkloadsCommand. Payloads with a cluster use the
+// cluster's lister from clusters, which is nil for a single cluster.
+func NewWorkloadsCommand(lister Lister, clusters *ClusterManager, options ...Option) *WorkloadsCommand {
 	w := &WorkloadsCommand{
-		lister: lister,
+		lister:   lister,
+		clusters: clusters,
+		options:  options,
 	}
 	return w
 }
@@ -52,28 +58,54 @@ func (wc *WorkloadsCommand) Run(ctx context.Context, w WebsocketWriter, c Comman
 	timer := time.NewTimer(0)
 	done := false
 
-	namespace, ok := c.Payload["namespace"].(string)
-	if !ok {
-		return fmt.Errorf("payload does not have a namespace")
+	scope, err := scopeFromPayload(c.Payload)
+	if err != nil {
+		return err
 	}
 
+	responseOptions, err := responseOptionsFromPayload(c.Payload)
+	if err != nil {
+		payload := map[string]interface{}{
+			"type": "error",
+			"data": newErrorMessage(err, http.StatusBadRequest),
+		}
+		return w.Write(c.CreateResponse(payload))
+	}
+
+	cluster, _ := c.Payload["cluster"].(string)
+	lister, err := wc.listerForCluster(cluster)
+	if err != nil {
+		payload := map[string]interface{}{
+			"type": "error",
+			"data": newErrorMessage(err, clusterErrorStatus(err)),
+		}
+		return w.Write(c.CreateResponse(payload))
+	}
+
+	layout := NewLayeredLayout()
+
 	for !done {
 		select {
 		case <-ctx.Done():
 			done = true
 			break
 		case <-timer.C:
-			nb := NewNodeBuilder(wc.lister)
-			nodes, err := nb.Build(namespace)
+			nb := NewNodeBuilder(lister, wc.options...)
+			nodes, err := nb.BuildScope(scope)
 			if err != nil {
 				return fmt.Errorf("build nodes: %w", err)
 			}
 
+			data := map[string]interface{}{
+				"nodes": responseOptions.ApplyWithLayout(nodes, layout),
+			}
+			if cluster != "" {
+				data["cluster"] = cluster
+			}
+
 			payload := map[string]interface{}{
 				"type": "nodes",
-				"data": map[string]interface{}{
-					"nodes": nodes,
-				},
+				"data": data,
 			}
 			resp := c.CreateResponse(payload)
 
@@ -87,3 +119,43 @@ func (wc *WorkloadsCommand) Run(ctx context.Context, w WebsocketWriter, c Comman
 
 	return nil
 }
+
+// listerForCluster returns the lister for a cluster. The command's lister is used if the cluster
+// is empty.
+func (wc *WorkloadsCommand) listerForCluster(cluster string) (Lister, error) {
+	if cluster == "" {
+		return wc.lister, nil
+	}
+
+	if wc.clusters == nil {
+		return nil, fmt.Errorf("%q: %w", cluster, ErrClusterNotFound)
+	}
+
+	return wc.clusters.Lister(cluster)
+}
+
+// scopeFromPayload creates a scope from a command payload. The payload has a namespace, a list of
+// namespaces, or sets allNamespaces.
+func scopeFromPayload(payload Payload) (Scope, error) {
+	scope := Scope{}
+
+	if namespace, ok := payload["namespace"].(string); ok && namespace != "" {
+		scope.Namespaces = append(scope.Namespaces, namespace)
+	}
+
+	namespaces, err := payloadStrings(payload, "namespaces")
+	if err != nil {
+		return Scope{}, err
+	}
+	scope.Namespaces = append(scope.Namespaces, namespaces...)
+
+	if allNamespaces, ok := payload["allNamespaces"].(bool); ok {
+		scope.AllNamespaces = allNamespaces
+	}
+
+	if len(scope.Namespaces) == 0 && !scope.AllNamespaces {
+		return Scope{}, fmt.Errorf("payload does not have a namespace")
+	}
+
+	return scope, nil
+}
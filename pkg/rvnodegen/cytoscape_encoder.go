@@ -0,0 +1,96 @@
+package rvnodegen
+
+import (
+	"io"
+
+	"k8s.io/apimachinery/pkg/util/json"
+)
+
+// CytoscapeGraphEncoder encodes nodes as Cytoscape.js elements JSON. Nodes in a group are
+// compound nodes with the group as their parent.
+type CytoscapeGraphEncoder struct{}
+
+var _ GraphEncoder = &CytoscapeGraphEncoder{}
+
+type cytoscapeGraph struct {
+	Elements cytoscapeElements `json:"elements"`
+}
+
+type cytoscapeElements struct {
+	Nodes []cytoscapeElement `json:"nodes"`
+	Edges []cytoscapeElement `json:"edges"`
+}
+
+type cytoscapeElement struct {
+	Data map[string]interface{} `json:"data"`
+}
+
+// Format is the name of the format.
+func (e *CytoscapeGraphEncoder) Format() string {
+	return "cytoscape"
+}
+
+// ContentType is the media type of the encoding.
+func (e *CytoscapeGraphEncoder) ContentType() string {
+	return "application/vnd.cytoscape.js+json"
+}
+
+// TypedEdges returns true because edges are labeled with their relation.
+func (e *CytoscapeGraphEncoder) TypedEdges() bool {
+	return true
+}
+
+// MetadataFields are the metadata fields included as node data.
+func (e *CytoscapeGraphEncoder) MetadataFields() []MetadataField {
+	return []MetadataField{MetadataFieldKind, MetadataFieldNamespace}
+}
+
+// Encode writes the nodes to w.
+func (e *CytoscapeGraphEncoder) Encode(w io.Writer, nodes []GraphNode) error {
+	graph := cytoscapeGraph{
+		Elements: cytoscapeElements{
+			Nodes: []cytoscapeElement{},
+			Edges: []cytoscapeElement{},
+		},
+	}
+
+	byID := nodesByID(nodes)
+
+	for _, node := range nodes {
+		data := map[string]interface{}{
+			"id":    node.ID,
+			"label": node.Label,
+			"group": node.IsGroup != nil,
+		}
+
+		if node.Parent != nil {
+			if _, ok := byID[*node.Parent]; ok {
+				data["parent"] = *node.Parent
+			}
+		}
+
+		for key, value := range graphNodeAttributes(node) {
+			data[key] = value
+		}
+
+		graph.Elements.Nodes = append(graph.Elements.Nodes, cytoscapeElement{Data: data})
+	}
+
+	for _, edge := range graphEdges(nodes) {
+		data := map[string]interface{}{
+			"id":     edgeKey(edge),
+			"source": edge.Source,
+			"target": edge.Target,
+		}
+
+		if edge.Relation != "" {
+			data["relation"] = string(edge.Relation)
+		}
+
+		graph.Elements.Edges = append(graph.Elements.Edges, cytoscapeElement{Data: data})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(graph)
+}
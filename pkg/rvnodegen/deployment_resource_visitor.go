@@ -0,0 +1,83 @@
+package rvnodegen
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// DeploymentResourceVisitor visits a deployment. It models the deployment's rollout history
+// from the replica sets it controls.
+type DeploymentResourceVisitor struct {
+	lister Lister
+}
+
+var _ ResourceVisitor = &DeploymentResourceVisitor{}
+
+// NewDeploymentResourceVisitor creates an instance of DeploymentResourceVisitor.
+func NewDeploymentResourceVisitor(lister Lister) *DeploymentResourceVisitor {
+	d := &DeploymentResourceVisitor{
+		lister: lister,
+	}
+	return d
+}
+
+// Name is the name of the resource visitor.
+func (d *DeploymentResourceVisitor) Name() string {
+	return "Deployment"
+}
+
+// Matches returns a group/version/kind that this resource visitor matches.
+func (d *DeploymentResourceVisitor) Matches(gvk schema.GroupVersionKind) bool {
+	return deploymentGVK.String() == gvk.String()
+}
+
+// Visit visits a deployment resource. Replica sets for old revisions that are scaled to zero
+// are collapsed into the rollout history unless revision history is expanded.
+func (d *DeploymentResourceVisitor) Visit(object *unstructured.Unstructured, node GraphNode, visitor *Visitor) (GraphNode, error) {
+	replicaSets, err := d.lister.ByNamespace(object.GetNamespace()).List(replicaSetGVK, labels.Everything())
+	if err != nil {
+		return GraphNode{}, fmt.Errorf("list replica sets: %w", err)
+	}
+
+	var controlled []*unstructured.Unstructured
+	for _, replicaSet := range replicaSets {
+		if metav1.IsControlledBy(replicaSet, object) {
+			controlled = append(controlled, replicaSet)
+		}
+	}
+
+	rollout, err := buildRollout(object, controlled, visitor.expandRevisionHistory)
+	if err != nil {
+		return GraphNode{}, fmt.Errorf("build rollout: %w", err)
+	}
+
+	if node.Extra == nil {
+		node.Extra = map[string]interface{}{}
+	}
+	node.Extra["rollout"] = rollout
+
+	if rollout.InProgress {
+		node.HealthStatus = worseHealthStatus(node.HealthStatus, HealthStatusTypeDegraded)
+	}
+
+	collapsed := map[string]bool{}
+	for _, r := range rollout.Revisions {
+		collapsed[r.Name] = r.Collapsed
+	}
+
+	for _, replicaSet := range controlled {
+		if collapsed[replicaSet.GetName()] {
+			continue
+		}
+
+		if err := visitor.Visit(false, replicaSet); err != nil {
+			return GraphNode{}, err
+		}
+	}
+
+	return node, nil
+}
@@ -0,0 +1,123 @@
+package rvnodegen
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// DOTGraphEncoder encodes nodes as a Graphviz DOT digraph. Groups are rendered as clusters,
+// nodes are colored by health and shaped by node type, and edges are labelled with their
+// relation.
+type DOTGraphEncoder struct{}
+
+var _ GraphEncoder = &DOTGraphEncoder{}
+
+// Format is the name of the format.
+func (e *DOTGraphEncoder) Format() string {
+	return "dot"
+}
+
+// ContentType is the media type of the encoding.
+func (e *DOTGraphEncoder) ContentType() string {
+	return "text/vnd.graphviz"
+}
+
+// TypedEdges returns true because edges are labeled with their relation.
+func (e *DOTGraphEncoder) TypedEdges() bool {
+	return true
+}
+
+// Encode writes the nodes to w.
+func (e *DOTGraphEncoder) Encode(w io.Writer, nodes []GraphNode) error {
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintln(bw, "digraph G {")
+	fmt.Fprintln(bw, "  compound=true;")
+	fmt.Fprintln(bw, "  rankdir=LR;")
+	fmt.Fprintln(bw, `  node [style="filled,rounded", fontname="Helvetica"];`)
+	fmt.Fprintln(bw, `  edge [fontname="Helvetica", fontsize=10];`)
+
+	tree := newGraphTree(nodes)
+	for _, node := range tree.roots {
+		writeDOTNode(bw, tree, node, "  ")
+	}
+
+	for _, edge := range graphEdges(nodes) {
+		attributes := ""
+		if edge.Relation != "" {
+			attributes = fmt.Sprintf(" [label=%s]", strconv.Quote(string(edge.Relation)))
+		}
+		fmt.Fprintf(bw, "  %s -> %s%s;\n", strconv.Quote(edge.Source), strconv.Quote(edge.Target), attributes)
+	}
+
+	fmt.Fprintln(bw, "}")
+
+	return bw.Flush()
+}
+
+// writeDOTNode writes a node. Groups are written as a cluster containing the group's own node,
+// so edges to the group have something to point at, and its members.
+func writeDOTNode(w io.Writer, tree graphTree, node GraphNode, indent string) {
+	children := tree.children[node.ID]
+	if node.IsGroup == nil && len(children) == 0 {
+		fmt.Fprintf(w, "%s%s;\n", indent, dotNodeStatement(node))
+		return
+	}
+
+	colors := colorsForHealth(node.HealthStatus)
+
+	fmt.Fprintf(w, "%ssubgraph %s {\n", indent, strconv.Quote("cluster_"+node.ID))
+	fmt.Fprintf(w, "%s  label=%s;\n", indent, strconv.Quote(node.Label))
+	fmt.Fprintf(w, "%s  style=\"rounded,dashed\";\n", indent)
+	fmt.Fprintf(w, "%s  color=%s;\n", indent, strconv.Quote(colors.stroke))
+	fmt.Fprintf(w, "%s  %s;\n", indent, dotNodeStatement(node))
+
+	for _, child := range children {
+		writeDOTNode(w, tree, child, indent+"  ")
+	}
+
+	fmt.Fprintf(w, "%s}\n", indent)
+}
+
+func dotNodeStatement(node GraphNode) string {
+	colors := colorsForHealth(node.HealthStatus)
+
+	attributes := []string{
+		"label=" + strconv.Quote(node.Label),
+		"shape=" + dotShape(node.NodeType),
+		"color=" + strconv.Quote(colors.stroke),
+		"fillcolor=" + strconv.Quote(colors.fill),
+	}
+
+	if node.HealthStatus == HealthStatusTypeMissing {
+		attributes = append(attributes, `style="filled,dashed"`)
+	}
+
+	return fmt.Sprintf("%s [%s]", strconv.Quote(node.ID), strings.Join(attributes, ", "))
+}
+
+func dotShape(nodeType NodeType) string {
+	switch nodeType {
+	case NodeTypeWorkload:
+		return "box"
+	case NodeTypeNetworking:
+		return "ellipse"
+	case NodeTypeConfiguration:
+		return "note"
+	case NodeTypeCustomResource:
+		return "component"
+	case NodeTypeStorage:
+		return "cylinder"
+	case NodeTypeInfrastructure:
+		return "box3d"
+	case NodeTypeGitOps:
+		return "hexagon"
+	case NodeTypeApplication, NodeTypeHelmRelease, NodeTypeNamespace:
+		return "tab"
+	default:
+		return "box"
+	}
+}
@@ -0,0 +1,127 @@
+package rvnodegen
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// EdgeRelation is the kind of relationship an edge represents.
+type EdgeRelation string
+
+const (
+	// EdgeRelationOwner is an object pointing to its owner.
+	EdgeRelationOwner EdgeRelation = "owner"
+	// EdgeRelationSelector is an object selecting another object with labels.
+	EdgeRelationSelector EdgeRelation = "selector"
+	// EdgeRelationVolume is an object mounting or binding storage.
+	EdgeRelationVolume EdgeRelation = "volume"
+	// EdgeRelationEnv is an object referencing configuration in its environment.
+	EdgeRelationEnv EdgeRelation = "env"
+	// EdgeRelationServiceAccount is an object running as or using a service account.
+	EdgeRelationServiceAccount EdgeRelation = "serviceAccount"
+	// EdgeRelationImagePullSecret is an object pulling images with a secret.
+	EdgeRelationImagePullSecret EdgeRelation = "imagePullSecret"
+	// EdgeRelationIngressBackend is an ingress routing to a backend.
+	EdgeRelationIngressBackend EdgeRelation = "ingressBackend"
+	// EdgeRelationRBAC is a binding granting a role to a subject.
+	EdgeRelationRBAC EdgeRelation = "rbac"
+	// EdgeRelationCustom is a relationship defined by a custom resource visitor.
+	EdgeRelationCustom EdgeRelation = "custom"
+)
+
+// Edge is a directed edge between two graph nodes.
+type Edge struct {
+	// Source is the id of the node the edge starts from.
+	Source string `json:"source"`
+
+	// Target is the id of the node the edge points to.
+	Target string `json:"target"`
+
+	// Relation is the kind of relationship.
+	Relation EdgeRelation `json:"relation"`
+
+	// Attributes are optional details about the relationship, e.g. a port or path.
+	Attributes map[string]string `json:"attributes,omitempty"`
+
+	// Provenance records how the edge was found.
+	Provenance *EdgeProvenance `json:"provenance,omitempty"`
+}
+
+// EdgeProvenance records the visitor and field that produced an edge.
+type EdgeProvenance struct {
+	// Visitor is the name of the visitor that created the edge.
+	Visitor string `json:"visitor"`
+
+	// Field is the field of the source object the edge was read from.
+	Field string `json:"field,omitempty"`
+
+	// Description explains the edge, e.g. "Pod api mounts Secret certs via volume certs".
+	Description string `json:"description,omitempty"`
+}
+
+// newEdgeProvenance creates provenance for an edge from source to target. The description is
+// built from the verb and an optional detail.
+func newEdgeProvenance(visitor, field string, source *unstructured.Unstructured, verb string, target *unstructured.Unstructured, detail string) EdgeProvenance {
+	subject := fmt.Sprintf("%s %s", source.GetKind(), source.GetName())
+
+	return EdgeProvenance{
+		Visitor:     visitor,
+		Field:       field,
+		Description: edgeDescription(subject, verb, target, detail),
+	}
+}
+
+// newPodTemplateProvenance creates provenance for an edge read from the pod template of a
+// controller. Controllers don't have pod specs of their own, so the edge is attributed to the
+// controller's pod template, e.g. "Deployment api's pod template mounts Secret certs". Pods
+// without a controller are attributed to the pod.
+func newPodTemplateProvenance(visitor, field string, controller, pod *unstructured.Unstructured, verb string, target *unstructured.Unstructured, detail string) EdgeProvenance {
+	if controller.GetUID() == pod.GetUID() {
+		return newEdgeProvenance(visitor, field, pod, verb, target, detail)
+	}
+
+	templateField := "spec.template."
+	if controller.GetKind() == cronJobGVK.Kind {
+		templateField = "spec.jobTemplate.spec.template."
+	}
+
+	subject := fmt.Sprintf("%s %s's pod template", controller.GetKind(), controller.GetName())
+
+	return EdgeProvenance{
+		Visitor:     visitor,
+		Field:       templateField + field,
+		Description: edgeDescription(subject, verb, target, detail),
+	}
+}
+
+func edgeDescription(subject, verb string, target *unstructured.Unstructured, detail string) string {
+	description := fmt.Sprintf("%s %s %s %s", subject, verb, target.GetKind(), target.GetName())
+	if detail != "" {
+		description += " via " + detail
+	}
+	return description
+}
+
+// addEdge adds an edge from a node to a target. The target is also added to the node's targets.
+func addEdge(node GraphNode, target string, relation EdgeRelation, attributes map[string]string, provenance EdgeProvenance) GraphNode {
+	for _, edge := range node.Edges {
+		if edge.Target == target && edge.Relation == relation {
+			return node
+		}
+	}
+
+	node.Edges = append(node.Edges, Edge{
+		Source:     node.ID,
+		Target:     target,
+		Relation:   relation,
+		Attributes: attributes,
+		Provenance: &provenance,
+	})
+
+	if !stringsIncludes(target, node.Targets) {
+		node.Targets = append(node.Targets, target)
+	}
+
+	return node
+}
@@ -0,0 +1,84 @@
+package rvnodegen
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// edgeReference is a reference from an object to another object. Visitors add an edge for it,
+// and subgraph traversals follow it.
+type edgeReference struct {
+	gvk        schema.GroupVersionKind
+	namespace  string
+	name       string
+	relation   EdgeRelation
+	attributes map[string]string
+	field      string
+	verb       string
+	detail     string
+	// optional references may be missing without being a misconfiguration.
+	optional bool
+}
+
+// edgeReferences returns the references an object's spec makes to other objects. Owner
+// references, selectors and GitOps tracking are not included.
+func edgeReferences(object *unstructured.Unstructured) ([]edgeReference, error) {
+	gvk := object.GroupVersionKind()
+
+	switch {
+	case isPod(object):
+		return podSpecReferences(object)
+	case gvk == serviceAccountGVK:
+		return serviceAccountReferences(object)
+	case gvk == ingressGVK:
+		return ingressReferences(object)
+	case gvk == persistentVolumeGVK:
+		return persistentVolumeReferences(object)
+	case gvk == clusterRoleBindingGVK:
+		return clusterRoleBindingReferences(object)
+	default:
+		return nil, nil
+	}
+}
+
+// visitEdgeReferences visits the objects an object references and adds edges to them to the
+// object's node. Missing referents that aren't optional are emitted as placeholders.
+func visitEdgeReferences(visitor *Visitor, name string, object *unstructured.Unstructured, references []edgeReference, node GraphNode) (GraphNode, error) {
+	for _, ref := range references {
+		target, ok, err := visitor.visitEdgeReference(object, ref)
+		if err != nil {
+			return GraphNode{}, err
+		}
+
+		if !ok {
+			continue
+		}
+
+		provenance := newEdgeProvenance(name, ref.field, object, ref.verb, target, ref.detail)
+		node = addEdge(node, string(target.GetUID()), ref.relation, ref.attributes, provenance)
+	}
+
+	return node, nil
+}
+
+// visitEdgeReference visits the object a reference points at. It returns false if the reference
+// is not followed because it is optional and missing, or because it leads from a cluster scoped
+// object into a namespace outside the visitor's namespaces.
+func (v *Visitor) visitEdgeReference(source *unstructured.Unstructured, ref edgeReference) (*unstructured.Unstructured, bool, error) {
+	if source.GetNamespace() == "" && ref.namespace != "" && !v.inScope(ref.namespace) {
+		return nil, false, nil
+	}
+
+	if ref.optional {
+		if _, err := getObject(v.lister, ref.gvk, ref.namespace, ref.name); isUnavailable(err) {
+			return nil, false, nil
+		}
+	}
+
+	object, err := v.visitReference(ref.gvk, ref.namespace, ref.name)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return object, true, nil
+}
@@ -12,25 +12,24 @@ type Emitter interface {
 
 // NodeEmitter is an emitter that contains graph nodes.
 type NodeEmitter struct {
-	nodes []GraphNode
+	nodes   []GraphNode
+	objects map[string]*unstructured.Unstructured
 }
 
 var _ Emitter = &NodeEmitter{}
 
 // NewNodeEmitter creates an instance of NodeEmitter.
 func NewNodeEmitter() *NodeEmitter {
-	n := &NodeEmitter{}
+	n := &NodeEmitter{
+		objects: map[string]*unstructured.Unstructured{},
+	}
 	return n
 }
 
 // Emit emits a graph node for an object.
 func (n *NodeEmitter) Emit(object *unstructured.Unstructured, graphNode GraphNode) error {
-	if isPod(object) {
-		// TODO search existing nodes for a pod with the same selector
-		return nil
-	}
-
 	n.nodes = append(n.nodes, graphNode)
+	n.objects[graphNode.ID] = object
 
 	return nil
 }
@@ -39,3 +38,8 @@ func (n *NodeEmitter) Emit(object *unstructured.Unstructured, graphNode GraphNod
 func (n *NodeEmitter) Nodes() []GraphNode {
 	return n.nodes
 }
+
+// Objects returns the emitted objects keyed by graph node id.
+func (n *NodeEmitter) Objects() map[string]*unstructured.Unstructured {
+	return n.objects
+}
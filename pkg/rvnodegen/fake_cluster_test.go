@@ -0,0 +1,216 @@
+package rvnodegen
+
+import (
+	"testing"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	discoveryfake "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+// fakeResource is a resource served by a fake cluster.
+type fakeResource struct {
+	gvk        schema.GroupVersionKind
+	resource   string
+	namespaced bool
+	// custom resources are defined by a custom resource definition.
+	custom bool
+}
+
+// fakeCoreResources are the resources every fake cluster serves.
+var fakeCoreResources = []fakeResource{
+	{gvk: namespaceGVK, resource: "namespaces"},
+	{gvk: crdGVK, resource: "customresourcedefinitions"},
+	{gvk: podGVK, resource: "pods", namespaced: true},
+	{gvk: serviceGVK, resource: "services", namespaced: true},
+	{gvk: configMapGVK, resource: "configmaps", namespaced: true},
+	{gvk: secretGVK, resource: "secrets", namespaced: true},
+}
+
+// fakePreferredDiscovery serves preferred resources, which the fake discovery client doesn't.
+type fakePreferredDiscovery struct {
+	*discoveryfake.FakeDiscovery
+	resources []*metav1.APIResourceList
+}
+
+func (d *fakePreferredDiscovery) ServerPreferredResources() ([]*metav1.APIResourceList, error) {
+	return d.resources, nil
+}
+
+// newFakeClusterLister creates a lister for a fake cluster serving the core resources and
+// resources. The user can read everything, and the informers are synced with objects and the
+// definitions of custom resources.
+func newFakeClusterLister(t *testing.T, resources []fakeResource, objects ...*unstructured.Unstructured) Lister {
+	t.Helper()
+
+	return newFakeInformerManager(t, resources, objects...).Lister()
+}
+
+// newFakeInformerManager creates an informer manager for a fake cluster like
+// newFakeClusterLister. Its informers are stopped when the test finishes.
+func newFakeInformerManager(t *testing.T, resources []fakeResource, objects ...*unstructured.Unstructured) *InformerManager {
+	t.Helper()
+
+	resources = append(append([]fakeResource(nil), fakeCoreResources...), resources...)
+
+	byGroupVersion := map[string]*metav1.APIResourceList{}
+	discovery := &fakePreferredDiscovery{FakeDiscovery: &discoveryfake.FakeDiscovery{Fake: &clienttesting.Fake{}}}
+	listKinds := map[schema.GroupVersionResource]string{}
+
+	for _, resource := range resources {
+		groupVersion := resource.gvk.GroupVersion().String()
+
+		list, ok := byGroupVersion[groupVersion]
+		if !ok {
+			list = &metav1.APIResourceList{GroupVersion: groupVersion}
+			byGroupVersion[groupVersion] = list
+			discovery.resources = append(discovery.resources, list)
+		}
+
+		list.APIResources = append(list.APIResources, metav1.APIResource{
+			Name:       resource.resource,
+			Namespaced: resource.namespaced,
+			Kind:       resource.gvk.Kind,
+			Verbs:      metav1.Verbs{"get", "list", "watch"},
+		})
+
+		listKinds[resource.gvk.GroupVersion().WithResource(resource.resource)] = resource.gvk.Kind + "List"
+
+		if resource.custom {
+			objects = append(objects, newTestCRD(resource))
+		}
+	}
+
+	var runtimeObjects []runtime.Object
+	for _, object := range objects {
+		runtimeObjects = append(runtimeObjects, object)
+	}
+
+	clientset := kubefake.NewSimpleClientset()
+	clientset.PrependReactor("create", "selfsubjectaccessreviews", func(clienttesting.Action) (bool, runtime.Object, error) {
+		review := &authorizationv1.SelfSubjectAccessReview{
+			Status: authorizationv1.SubjectAccessReviewStatus{Allowed: true},
+		}
+		return true, review, nil
+	})
+
+	client := &Client{
+		discoveryClient:     discovery,
+		dynamicClient:       dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds, runtimeObjects...),
+		authorizationClient: clientset.AuthorizationV1(),
+	}
+
+	im, err := NewInformerManager(client)
+	if err != nil {
+		t.Fatalf("create informer manager: %v", err)
+	}
+	t.Cleanup(im.Stop)
+
+	return im
+}
+
+// newTestObject creates an object. Its uid is derived from its kind, namespace and name.
+func newTestObject(gvk schema.GroupVersionKind, namespace, name string) *unstructured.Unstructured {
+	object := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	object.SetGroupVersionKind(gvk)
+	object.SetNamespace(namespace)
+	object.SetName(name)
+	object.SetUID(types.UID(gvk.Kind + "/" + namespace + "/" + name))
+	return object
+}
+
+// newTestCRD creates the custom resource definition for a resource.
+func newTestCRD(resource fakeResource) *unstructured.Unstructured {
+	crd := newTestObject(crdGVK, "", resource.resource+"."+resource.gvk.Group)
+
+	scope := "Cluster"
+	if resource.namespaced {
+		scope = "Namespaced"
+	}
+
+	crd.Object["spec"] = map[string]interface{}{
+		"group": resource.gvk.Group,
+		"scope": scope,
+		"names": map[string]interface{}{
+			"kind":   resource.gvk.Kind,
+			"plural": resource.resource,
+		},
+	}
+
+	return crd
+}
+
+// visitWithResourceVisitor visits objects with a resource visitor and returns the emitted nodes by
+// id.
+func visitWithResourceVisitor(t *testing.T, lister Lister, resourceVisitor ResourceVisitor, objects []*unstructured.Unstructured, options ...Option) map[string]GraphNode {
+	t.Helper()
+
+	emitter := NewNodeEmitter()
+	visitor, err := NewVisitor(emitter, lister, []ResourceVisitor{resourceVisitor}, options...)
+	if err != nil {
+		t.Fatalf("create visitor: %v", err)
+	}
+
+	if err := visitor.Visit(false, objects...); err != nil {
+		t.Fatalf("visit: %v", err)
+	}
+
+	return nodesByID(emitter.Nodes())
+}
+
+// nodeParent returns the parent of a node, or an empty string if it has none.
+func nodeParent(node GraphNode) string {
+	if node.Parent == nil {
+		return ""
+	}
+	return *node.Parent
+}
+
+// fakeWorkloadResources are the resources a fake cluster serves for deployments.
+var fakeWorkloadResources = []fakeResource{
+	{gvk: deploymentGVK, resource: "deployments", namespaced: true},
+	{gvk: replicaSetGVK, resource: "replicasets", namespaced: true},
+}
+
+// setTestController makes controller the controller of an object.
+func setTestController(object, controller *unstructured.Unstructured) {
+	object.SetOwnerReferences([]metav1.OwnerReference{
+		*metav1.NewControllerRef(controller, controller.GroupVersionKind()),
+	})
+}
+
+// newTestDeployment creates a deployment with a replica set and a pod that mounts a config map.
+func newTestDeployment(namespace, name, configMap string) (deployment, replicaSet, pod *unstructured.Unstructured) {
+	deployment = newTestObject(deploymentGVK, namespace, name)
+
+	replicaSet = newTestObject(replicaSetGVK, namespace, name+"-abc")
+	setTestController(replicaSet, deployment)
+
+	pod = newTestObject(podGVK, namespace, name+"-abc-1")
+	setTestController(pod, replicaSet)
+	_ = unstructured.SetNestedSlice(pod.Object, []interface{}{
+		map[string]interface{}{
+			"name":      "config",
+			"configMap": map[string]interface{}{"name": configMap},
+		},
+	}, "spec", "volumes")
+
+	return deployment, replicaSet, pod
+}
+
+// objectReference returns the reference to an object by kind and name.
+func objectReference(object *unstructured.Unstructured) ObjectReference {
+	return ObjectReference{
+		APIVersion: object.GetAPIVersion(),
+		Kind:       object.GetKind(),
+		Namespace:  object.GetNamespace(),
+		Name:       object.GetName(),
+	}
+}
@@ -0,0 +1,424 @@
+package rvnodegen
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// FilterError is a filter expression parse error.
+type FilterError struct {
+	// Expression is the filter expression.
+	Expression string `json:"expression"`
+	// Position is the byte offset of the error in the expression.
+	Position int `json:"position"`
+	// Message describes the error.
+	Message string `json:"message"`
+}
+
+var _ error = &FilterError{}
+
+func (e *FilterError) Error() string {
+	return fmt.Sprintf("invalid filter at position %d: %s", e.Position, e.Message)
+}
+
+// Filter is a parsed filter expression. Expressions compare node fields with values, e.g.
+//
+//	kind in (Deployment,Service) and health != Healthy and label.team = payments
+//
+// Supported operators are =, !=, in, notin, and, or, not and parentheses. Fields are id, name,
+// kind, namespace, health, nodeType, status, group, label.<key> and annotation.<key>.
+type Filter struct {
+	expression string
+	root       filterExpr
+}
+
+// ParseFilter parses a filter expression.
+func ParseFilter(expression string) (*Filter, error) {
+	tokens, err := lexFilter(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &filterParser{expression: expression, tokens: tokens}
+
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if tok := p.peek(); tok.kind != filterTokenEOF {
+		return nil, p.errorf(tok, "unexpected %q", tok.value)
+	}
+
+	return &Filter{expression: expression, root: root}, nil
+}
+
+// String returns the filter expression.
+func (f *Filter) String() string {
+	return f.expression
+}
+
+// Matches returns true if a node matches the filter.
+func (f *Filter) Matches(node GraphNode) bool {
+	return f.root.matches(node)
+}
+
+// FilterNodes returns the nodes that match a filter, along with neighbors up to a number of
+// hops away. Targets and edges to nodes outside of the result are removed.
+func FilterNodes(nodes []GraphNode, filter *Filter, hops int) []GraphNode {
+	if filter == nil {
+		return nodes
+	}
+
+	var seeds []string
+	for _, node := range nodes {
+		if filter.Matches(node) {
+			seeds = append(seeds, node.ID)
+		}
+	}
+
+	return neighborhood(nodes, seeds, hops)
+}
+
+type filterExpr interface {
+	matches(node GraphNode) bool
+}
+
+type filterAnd struct{ left, right filterExpr }
+
+func (e filterAnd) matches(node GraphNode) bool { return e.left.matches(node) && e.right.matches(node) }
+
+type filterOr struct{ left, right filterExpr }
+
+func (e filterOr) matches(node GraphNode) bool { return e.left.matches(node) || e.right.matches(node) }
+
+type filterNot struct{ expr filterExpr }
+
+func (e filterNot) matches(node GraphNode) bool { return !e.expr.matches(node) }
+
+type filterComparison struct {
+	field  string
+	negate bool
+	values []string
+}
+
+func (e filterComparison) matches(node GraphNode) bool {
+	actual := filterFieldValue(node, e.field)
+
+	found := false
+	for _, value := range e.values {
+		if actual == value {
+			found = true
+			break
+		}
+	}
+
+	return found != e.negate
+}
+
+var filterFields = []string{"id", "name", "kind", "namespace", "health", "nodeType", "status", "group"}
+
+func isFilterField(field string) bool {
+	if strings.HasPrefix(field, "label.") || strings.HasPrefix(field, "annotation.") {
+		return true
+	}
+
+	return stringsIncludes(field, filterFields)
+}
+
+func filterFieldValue(node GraphNode, field string) string {
+	metadata := node.Metadata
+	if metadata == nil {
+		metadata = &NodeMetadata{}
+	}
+
+	switch {
+	case strings.HasPrefix(field, "label."):
+		return metadata.Labels[strings.TrimPrefix(field, "label.")]
+	case strings.HasPrefix(field, "annotation."):
+		return metadata.Annotations[strings.TrimPrefix(field, "annotation.")]
+	}
+
+	switch field {
+	case "id":
+		return node.ID
+	case "name":
+		return node.Label
+	case "kind":
+		return metadata.Kind
+	case "namespace":
+		return metadata.Namespace
+	case "health":
+		return string(node.HealthStatus)
+	case "nodeType":
+		return string(node.NodeType)
+	case "status":
+		return metadata.Status
+	case "group":
+		if node.IsGroup != nil {
+			return "true"
+		}
+		return "false"
+	default:
+		return ""
+	}
+}
+
+type filterTokenKind int
+
+const (
+	filterTokenEOF filterTokenKind = iota
+	filterTokenWord
+	filterTokenString
+	filterTokenEquals
+	filterTokenNotEquals
+	filterTokenLParen
+	filterTokenRParen
+	filterTokenComma
+)
+
+type filterToken struct {
+	kind  filterTokenKind
+	value string
+	pos   int
+}
+
+// isKeyword returns true if the token is an unquoted keyword.
+func (t filterToken) isKeyword(keyword string) bool {
+	return t.kind == filterTokenWord && strings.EqualFold(t.value, keyword)
+}
+
+func isFilterWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("._/-:", r)
+}
+
+func lexFilter(expression string) ([]filterToken, error) {
+	var tokens []filterToken
+	runes := []rune(expression)
+
+	// positions are reported as byte offsets.
+	offset := func(i int) int { return len(string(runes[:i])) }
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, filterToken{kind: filterTokenLParen, value: "(", pos: offset(i)})
+			i++
+		case r == ')':
+			tokens = append(tokens, filterToken{kind: filterTokenRParen, value: ")", pos: offset(i)})
+			i++
+		case r == ',':
+			tokens = append(tokens, filterToken{kind: filterTokenComma, value: ",", pos: offset(i)})
+			i++
+		case r == '=':
+			start := i
+			i++
+			if i < len(runes) && runes[i] == '=' {
+				i++
+			}
+			tokens = append(tokens, filterToken{kind: filterTokenEquals, value: string(runes[start:i]), pos: offset(start)})
+		case r == '!':
+			if i+1 >= len(runes) || runes[i+1] != '=' {
+				return nil, &FilterError{Expression: expression, Position: offset(i), Message: "expected !="}
+			}
+			tokens = append(tokens, filterToken{kind: filterTokenNotEquals, value: "!=", pos: offset(i)})
+			i += 2
+		case r == '"' || r == '\'':
+			start := i
+			i++
+			var sb strings.Builder
+			for i < len(runes) && runes[i] != r {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				}
+				sb.WriteRune(runes[i])
+				i++
+			}
+			if i >= len(runes) {
+				return nil, &FilterError{Expression: expression, Position: offset(start), Message: "unterminated string"}
+			}
+			i++
+			tokens = append(tokens, filterToken{kind: filterTokenString, value: sb.String(), pos: offset(start)})
+		case isFilterWordRune(r):
+			start := i
+			for i < len(runes) && isFilterWordRune(runes[i]) {
+				i++
+			}
+			tokens = append(tokens, filterToken{kind: filterTokenWord, value: string(runes[start:i]), pos: offset(start)})
+		default:
+			return nil, &FilterError{Expression: expression, Position: offset(i), Message: fmt.Sprintf("unexpected character %q", r)}
+		}
+	}
+
+	tokens = append(tokens, filterToken{kind: filterTokenEOF, pos: len(expression)})
+
+	return tokens, nil
+}
+
+type filterParser struct {
+	expression string
+	tokens     []filterToken
+	pos        int
+}
+
+func (p *filterParser) peek() filterToken {
+	return p.tokens[p.pos]
+}
+
+func (p *filterParser) next() filterToken {
+	tok := p.tokens[p.pos]
+	if tok.kind != filterTokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *filterParser) errorf(tok filterToken, format string, args ...interface{}) error {
+	return &FilterError{
+		Expression: p.expression,
+		Position:   tok.pos,
+		Message:    fmt.Sprintf(format, args...),
+	}
+}
+
+func (p *filterParser) parseOr() (filterExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().isKeyword("or") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = filterOr{left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (filterExpr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().isKeyword("and") {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = filterAnd{left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *filterParser) parseUnary() (filterExpr, error) {
+	if p.peek().isKeyword("not") {
+		p.next()
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return filterNot{expr: expr}, nil
+	}
+
+	if p.peek().kind == filterTokenLParen {
+		p.next()
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		if tok := p.next(); tok.kind != filterTokenRParen {
+			return nil, p.errorf(tok, "expected )")
+		}
+
+		return expr, nil
+	}
+
+	return p.parseComparison()
+}
+
+func (p *filterParser) parseComparison() (filterExpr, error) {
+	fieldTok := p.next()
+	if fieldTok.kind != filterTokenWord {
+		return nil, p.errorf(fieldTok, "expected a field")
+	}
+
+	if !isFilterField(fieldTok.value) {
+		return nil, p.errorf(fieldTok, "unknown field %q", fieldTok.value)
+	}
+
+	c := filterComparison{field: fieldTok.value}
+
+	opTok := p.next()
+	switch {
+	case opTok.kind == filterTokenEquals || opTok.kind == filterTokenNotEquals:
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		c.negate = opTok.kind == filterTokenNotEquals
+		c.values = []string{value}
+	case opTok.isKeyword("in") || opTok.isKeyword("notin"):
+		values, err := p.parseValueList()
+		if err != nil {
+			return nil, err
+		}
+		c.negate = opTok.isKeyword("notin")
+		c.values = values
+	case opTok.isKeyword("not") && p.peek().isKeyword("in"):
+		p.next()
+		values, err := p.parseValueList()
+		if err != nil {
+			return nil, err
+		}
+		c.negate = true
+		c.values = values
+	default:
+		return nil, p.errorf(opTok, "expected an operator after %q", fieldTok.value)
+	}
+
+	return c, nil
+}
+
+func (p *filterParser) parseValue() (string, error) {
+	tok := p.next()
+	if tok.kind != filterTokenWord && tok.kind != filterTokenString {
+		return "", p.errorf(tok, "expected a value")
+	}
+	return tok.value, nil
+}
+
+func (p *filterParser) parseValueList() ([]string, error) {
+	if tok := p.next(); tok.kind != filterTokenLParen {
+		return nil, p.errorf(tok, "expected (")
+	}
+
+	var values []string
+	for {
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+
+		tok := p.next()
+		if tok.kind == filterTokenRParen {
+			return values, nil
+		}
+		if tok.kind != filterTokenComma {
+			return nil, p.errorf(tok, "expected , or )")
+		}
+	}
+}
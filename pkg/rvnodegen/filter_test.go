@@ -0,0 +1,122 @@
+package rvnodegen
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseFilter(t *testing.T) {
+	group := "true"
+	nodes := []GraphNode{
+		{
+			ID:           "deployment",
+			Label:        "web",
+			HealthStatus: HealthStatusTypeHealthy,
+			Metadata: &NodeMetadata{
+				Kind:        "Deployment",
+				Namespace:   "default",
+				Labels:      map[string]string{"team": "payments"},
+				Annotations: map[string]string{"owner": "a b"},
+			},
+		},
+		{
+			ID:           "service",
+			Label:        "web",
+			HealthStatus: HealthStatusTypeDegraded,
+			Metadata: &NodeMetadata{
+				Kind:        "Service",
+				Namespace:   "default",
+				Labels:      map[string]string{"team": "search"},
+				Annotations: map[string]string{"note": "it's"},
+			},
+		},
+		{
+			ID:      "group",
+			Label:   "default",
+			IsGroup: &group,
+		},
+	}
+
+	tests := []struct {
+		expression string
+		want       []string
+	}{
+		{expression: "kind = Deployment", want: []string{"deployment"}},
+		{expression: "kind == Service", want: []string{"service"}},
+		{expression: "kind in (Deployment,Service) and health != Healthy", want: []string{"service"}},
+		{expression: "kind = Service or kind = Deployment and health = Degraded", want: []string{"service"}},
+		{expression: "(kind = Service or kind = Deployment) and health = Healthy", want: []string{"deployment"}},
+		{expression: "not kind = Service", want: []string{"deployment", "group"}},
+		{expression: "kind not in (Service)", want: []string{"deployment", "group"}},
+		{expression: "kind notin (Service, Deployment)", want: []string{"group"}},
+		{expression: "annotation.owner = 'a b'", want: []string{"deployment"}},
+		{expression: `annotation.note = 'it\'s'`, want: []string{"service"}},
+		{expression: `name = "web" AND namespace = default`, want: []string{"deployment", "service"}},
+		{expression: "label.team != payments and group = false", want: []string{"service"}},
+		{expression: "group = true", want: []string{"group"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.expression, func(t *testing.T) {
+			filter, err := ParseFilter(test.expression)
+			if err != nil {
+				t.Fatalf("ParseFilter() error = %v", err)
+			}
+
+			var got []string
+			for _, node := range nodes {
+				if filter.Matches(node) {
+					got = append(got, node.ID)
+				}
+			}
+
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("matches = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseFilter_invalid(t *testing.T) {
+	tests := []struct {
+		expression string
+		position   int
+		message    string
+	}{
+		{expression: "", position: 0, message: "expected a field"},
+		{expression: "size = 1", position: 0, message: `unknown field "size"`},
+		{expression: "kind Pod", position: 5, message: `expected an operator after "kind"`},
+		{expression: "kind = ", position: 7, message: "expected a value"},
+		{expression: "kind ! Pod", position: 5, message: "expected !="},
+		{expression: "name = 'web", position: 7, message: "unterminated string"},
+		{expression: "kind in Pod", position: 8, message: "expected ("},
+		{expression: "kind in (Pod Service)", position: 13, message: "expected , or )"},
+		{expression: "(kind = Pod", position: 11, message: "expected )"},
+		{expression: "kind = Pod Service", position: 11, message: `unexpected "Service"`},
+		{expression: "kind = Pod & name = web", position: 11, message: `unexpected character '&'`},
+		// positions are byte offsets, so the two byte é moves the error by one.
+		{expression: "name = é !x", position: 10, message: "expected !="},
+	}
+
+	for _, test := range tests {
+		t.Run(test.expression, func(t *testing.T) {
+			_, err := ParseFilter(test.expression)
+
+			var filterErr *FilterError
+			if !errors.As(err, &filterErr) {
+				t.Fatalf("ParseFilter() error = %v, want a FilterError", err)
+			}
+
+			if filterErr.Position != test.position {
+				t.Errorf("position = %d, want %d", filterErr.Position, test.position)
+			}
+			if filterErr.Message != test.message {
+				t.Errorf("message = %q, want %q", filterErr.Message, test.message)
+			}
+			if filterErr.Expression != test.expression {
+				t.Errorf("expression = %q, want %q", filterErr.Expression, test.expression)
+			}
+		})
+	}
+}
@@ -0,0 +1,229 @@
+package rvnodegen
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// fluxOwnerLabels are the labels Flux sets on the objects it applies.
+var fluxOwnerLabels = []struct {
+	gvk       schema.GroupVersionKind
+	name      string
+	namespace string
+}{
+	{gvk: fluxKustomizationGVK, name: "kustomize.toolkit.fluxcd.io/name", namespace: "kustomize.toolkit.fluxcd.io/namespace"},
+	{gvk: fluxHelmReleaseGVK, name: "helm.toolkit.fluxcd.io/name", namespace: "helm.toolkit.fluxcd.io/namespace"},
+}
+
+// FluxVisitor visits objects managed by Flux. Objects are placed in a group for the
+// Kustomization or HelmRelease that manages them. Managers are found using Flux's owner labels
+// or a Kustomization's inventory. Flux kinds are read with the version the lister serves.
+type FluxVisitor struct {
+	lister         Lister
+	served         map[schema.GroupKind]schema.GroupVersionKind
+	kustomizations []*unstructured.Unstructured
+	listed         bool
+}
+
+var _ ResourceVisitor = &FluxVisitor{}
+
+// NewFluxVisitor creates an instance of FluxVisitor.
+func NewFluxVisitor(lister Lister) *FluxVisitor {
+	f := &FluxVisitor{
+		lister: lister,
+		served: map[schema.GroupKind]schema.GroupVersionKind{},
+	}
+	return f
+}
+
+// Name is the name of the resource visitor.
+func (f *FluxVisitor) Name() string {
+	return "Flux"
+}
+
+// Matches returns a group/version/kind that this resource visitor matches. Any object can
+// be managed by Flux.
+func (f *FluxVisitor) Matches(gvk schema.GroupVersionKind) bool {
+	return true
+}
+
+// Visit visits an object that could be managed by Flux.
+func (f *FluxVisitor) Visit(object *unstructured.Unstructured, node GraphNode, visitor *Visitor) (GraphNode, error) {
+	if isGroupKindMatch(object.GroupVersionKind().GroupKind(), []schema.GroupVersionKind{fluxKustomizationGVK, fluxHelmReleaseGVK}) {
+		var err error
+		node, err = fluxControllerNode(object, node)
+		if err != nil {
+			return GraphNode{}, err
+		}
+	}
+
+	controller, err := f.managingController(object)
+	if err != nil {
+		return GraphNode{}, err
+	}
+
+	if controller == nil {
+		return node, nil
+	}
+
+	return setGitOpsParent(controller, node, visitor)
+}
+
+// managingController returns the Kustomization or HelmRelease managing an object, or nil if
+// there isn't one.
+func (f *FluxVisitor) managingController(object *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	controller, err := f.findController(object)
+	if err != nil {
+		return nil, err
+	}
+
+	if controller == nil || controller.GetUID() == object.GetUID() {
+		return nil, nil
+	}
+
+	return controller, nil
+}
+
+func (f *FluxVisitor) findController(object *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	objectLabels := object.GetLabels()
+
+	for _, ownerLabel := range fluxOwnerLabels {
+		name := objectLabels[ownerLabel.name]
+		namespace := objectLabels[ownerLabel.namespace]
+		if name == "" || namespace == "" {
+			continue
+		}
+
+		gvk, err := f.servedKind(ownerLabel.gvk)
+		if err != nil {
+			return nil, err
+		}
+
+		controller, err := f.lister.ByNamespace(namespace).Get(gvk, name)
+		if err != nil {
+			if isUnavailable(err) {
+				continue
+			}
+			return nil, fmt.Errorf("get flux %s %s/%s: %w", gvk.Kind, namespace, name, err)
+		}
+
+		return controller, nil
+	}
+
+	return f.findInInventory(object)
+}
+
+// findInInventory finds the Kustomization whose inventory contains an object. Inventory
+// entries have the format <namespace>_<name>_<group>_<kind>.
+func (f *FluxVisitor) findInInventory(object *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	if !f.listed {
+		gvk, err := f.servedKind(fluxKustomizationGVK)
+		if err != nil {
+			return nil, err
+		}
+
+		kustomizations, err := f.lister.List(gvk, labels.Everything())
+		if err != nil && !isUnavailable(err) {
+			return nil, fmt.Errorf("list flux kustomizations: %w", err)
+		}
+
+		f.kustomizations = kustomizations
+		f.listed = true
+	}
+
+	gvk := object.GroupVersionKind()
+	id := strings.Join([]string{object.GetNamespace(), object.GetName(), gvk.Group, gvk.Kind}, "_")
+
+	for _, kustomization := range f.kustomizations {
+		entries, _, err := unstructured.NestedSlice(kustomization.Object, "status", "inventory", "entries")
+		if err != nil {
+			return nil, fmt.Errorf("get kustomization inventory: %w", err)
+		}
+
+		for i := range entries {
+			entry, ok := entries[i].(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			if entryID, _, _ := unstructured.NestedString(entry, "id"); entryID == id {
+				return kustomization, nil
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+// servedKind returns the version of a Flux kind the lister serves.
+func (f *FluxVisitor) servedKind(gvk schema.GroupVersionKind) (schema.GroupVersionKind, error) {
+	if served, ok := f.served[gvk.GroupKind()]; ok {
+		return served, nil
+	}
+
+	served, err := servedKind(f.lister, gvk)
+	if err != nil {
+		return schema.GroupVersionKind{}, fmt.Errorf("resolve flux %s version: %w", gvk.Kind, err)
+	}
+
+	f.served[gvk.GroupKind()] = served
+	return served, nil
+}
+
+func fluxControllerNode(object *unstructured.Unstructured, node GraphNode) (GraphNode, error) {
+	ready, found, err := findCondition(object, "Ready")
+	if err != nil {
+		return GraphNode{}, fmt.Errorf("get ready condition: %w", err)
+	}
+
+	lastApplied, _, err := unstructured.NestedString(object.Object, "status", "lastAppliedRevision")
+	if err != nil {
+		return GraphNode{}, fmt.Errorf("get last applied revision: %w", err)
+	}
+
+	lastAttempted, _, err := unstructured.NestedString(object.Object, "status", "lastAttemptedRevision")
+	if err != nil {
+		return GraphNode{}, fmt.Errorf("get last attempted revision: %w", err)
+	}
+
+	suspended, _, err := unstructured.NestedBool(object.Object, "spec", "suspend")
+	if err != nil {
+		return GraphNode{}, fmt.Errorf("get suspend: %w", err)
+	}
+
+	switch {
+	case found && ready.Status == "True":
+		node.HealthStatus = HealthStatusTypeHealthy
+	case found && ready.Status == "False":
+		node.HealthStatus = HealthStatusTypeFailure
+	default:
+		// reconciliation is in progress
+		node.HealthStatus = HealthStatusTypeDegraded
+	}
+
+	syncStatus := SyncStatusUnknown
+	switch {
+	case lastApplied != "" && lastApplied == lastAttempted:
+		syncStatus = SyncStatusSynced
+	case lastAttempted != "":
+		syncStatus = SyncStatusOutOfSync
+	}
+
+	node.NodeType = NodeTypeGitOps
+
+	if node.Extra == nil {
+		node.Extra = map[string]interface{}{}
+	}
+	node.Extra["controller"] = "flux"
+	node.Extra["ready"] = ready.Status
+	node.Extra["reason"] = ready.Reason
+	node.Extra["syncStatus"] = syncStatus
+	node.Extra["revision"] = lastApplied
+	node.Extra["suspended"] = suspended
+
+	return node, nil
+}
@@ -0,0 +1,207 @@
+package rvnodegen
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func fluxResources(kustomizationVersion, helmReleaseVersion string) []fakeResource {
+	return []fakeResource{
+		{gvk: fluxKustomizationGVK.GroupKind().WithVersion(kustomizationVersion), resource: "kustomizations", namespaced: true, custom: true},
+		{gvk: fluxHelmReleaseGVK.GroupKind().WithVersion(helmReleaseVersion), resource: "helmreleases", namespaced: true, custom: true},
+	}
+}
+
+func newTestFluxController(gvk schema.GroupVersionKind, namespace, name, ready string) *unstructured.Unstructured {
+	controller := newTestObject(gvk, namespace, name)
+	_ = unstructured.SetNestedSlice(controller.Object, []interface{}{
+		map[string]interface{}{"type": "Ready", "status": ready, "reason": "ReconciliationSucceeded"},
+	}, "status", "conditions")
+	_ = unstructured.SetNestedField(controller.Object, "main/abc123", "status", "lastAppliedRevision")
+	_ = unstructured.SetNestedField(controller.Object, "main/abc123", "status", "lastAttemptedRevision")
+	return controller
+}
+
+func TestFluxVisitor_Visit(t *testing.T) {
+	kustomized := newTestObject(podGVK, "default", "kustomized")
+	kustomized.SetLabels(map[string]string{
+		"kustomize.toolkit.fluxcd.io/name":      "apps",
+		"kustomize.toolkit.fluxcd.io/namespace": "flux-system",
+	})
+
+	released := newTestObject(podGVK, "default", "released")
+	released.SetLabels(map[string]string{
+		"helm.toolkit.fluxcd.io/name":      "podinfo",
+		"helm.toolkit.fluxcd.io/namespace": "flux-system",
+	})
+
+	inventoried := newTestObject(podGVK, "default", "inventoried")
+
+	unmanaged := newTestObject(podGVK, "default", "unmanaged")
+
+	tests := []struct {
+		name                 string
+		kustomizationVersion string
+		helmReleaseVersion   string
+		object               *unstructured.Unstructured
+		wantParentKind       string
+	}{
+		{
+			name:                 "kustomization labels",
+			kustomizationVersion: "v1beta1",
+			helmReleaseVersion:   "v2beta1",
+			object:               kustomized,
+			wantParentKind:       "Kustomization",
+		},
+		{
+			name:                 "kustomization labels with served version",
+			kustomizationVersion: "v1",
+			helmReleaseVersion:   "v2",
+			object:               kustomized,
+			wantParentKind:       "Kustomization",
+		},
+		{
+			name:                 "helm release labels",
+			kustomizationVersion: "v1beta1",
+			helmReleaseVersion:   "v2beta1",
+			object:               released,
+			wantParentKind:       "HelmRelease",
+		},
+		{
+			name:                 "helm release labels with served version",
+			kustomizationVersion: "v1",
+			helmReleaseVersion:   "v2",
+			object:               released,
+			wantParentKind:       "HelmRelease",
+		},
+		{
+			name:                 "kustomization inventory with served version",
+			kustomizationVersion: "v1",
+			helmReleaseVersion:   "v2",
+			object:               inventoried,
+			wantParentKind:       "Kustomization",
+		},
+		{
+			name:                 "unmanaged",
+			kustomizationVersion: "v1",
+			helmReleaseVersion:   "v2",
+			object:               unmanaged,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resources := fluxResources(test.kustomizationVersion, test.helmReleaseVersion)
+
+			kustomization := newTestFluxController(resources[0].gvk, "flux-system", "apps", "True")
+			_ = unstructured.SetNestedSlice(kustomization.Object, []interface{}{
+				map[string]interface{}{"id": "default_inventoried__Pod", "v": "v1"},
+			}, "status", "inventory", "entries")
+
+			helmRelease := newTestFluxController(resources[1].gvk, "flux-system", "podinfo", "True")
+
+			lister := newFakeClusterLister(t, resources, test.object, kustomization, helmRelease)
+
+			nodes := visitWithResourceVisitor(t, lister, NewFluxVisitor(lister),
+				[]*unstructured.Unstructured{test.object})
+
+			node, ok := nodes[string(test.object.GetUID())]
+			if !ok {
+				t.Fatalf("node for %s was not emitted", test.object.GetName())
+			}
+
+			var wantParent string
+			switch test.wantParentKind {
+			case "Kustomization":
+				wantParent = string(kustomization.GetUID())
+			case "HelmRelease":
+				wantParent = string(helmRelease.GetUID())
+			}
+
+			if got := nodeParent(node); got != wantParent {
+				t.Errorf("parent = %q, want %q", got, wantParent)
+			}
+
+			if wantParent == "" {
+				return
+			}
+
+			parent, ok := nodes[wantParent]
+			if !ok {
+				t.Fatalf("%s was not emitted", test.wantParentKind)
+			}
+
+			if parent.NodeType != NodeTypeGitOps {
+				t.Errorf("%s node type = %q, want %q", test.wantParentKind, parent.NodeType, NodeTypeGitOps)
+			}
+		})
+	}
+}
+
+func TestFluxVisitor_Visit_controller(t *testing.T) {
+	tests := []struct {
+		name           string
+		ready          string
+		lastAttempted  string
+		wantHealth     HealthStatusType
+		wantSyncStatus string
+	}{
+		{name: "ready", ready: "True", lastAttempted: "main/abc123", wantHealth: HealthStatusTypeHealthy, wantSyncStatus: SyncStatusSynced},
+		{name: "not ready", ready: "False", lastAttempted: "main/def456", wantHealth: HealthStatusTypeFailure, wantSyncStatus: SyncStatusOutOfSync},
+		{name: "reconciling", ready: "Unknown", lastAttempted: "main/abc123", wantHealth: HealthStatusTypeDegraded, wantSyncStatus: SyncStatusSynced},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resources := fluxResources("v1", "v2")
+
+			kustomization := newTestFluxController(resources[0].gvk, "flux-system", "apps", test.ready)
+			_ = unstructured.SetNestedField(kustomization.Object, test.lastAttempted, "status", "lastAttemptedRevision")
+
+			lister := newFakeClusterLister(t, resources, kustomization)
+
+			nodes := visitWithResourceVisitor(t, lister, NewFluxVisitor(lister),
+				[]*unstructured.Unstructured{kustomization})
+
+			node, ok := nodes[string(kustomization.GetUID())]
+			if !ok {
+				t.Fatalf("kustomization node was not emitted")
+			}
+
+			if node.NodeType != NodeTypeGitOps {
+				t.Errorf("node type = %q, want %q", node.NodeType, NodeTypeGitOps)
+			}
+
+			if node.HealthStatus != test.wantHealth {
+				t.Errorf("health status = %q, want %q", node.HealthStatus, test.wantHealth)
+			}
+
+			if got := node.Extra["syncStatus"]; got != test.wantSyncStatus {
+				t.Errorf("sync status = %v, want %q", got, test.wantSyncStatus)
+			}
+		})
+	}
+}
+
+func TestFluxVisitor_Visit_notInstalled(t *testing.T) {
+	object := newTestObject(podGVK, "default", "kustomized")
+	object.SetLabels(map[string]string{
+		"kustomize.toolkit.fluxcd.io/name":      "apps",
+		"kustomize.toolkit.fluxcd.io/namespace": "flux-system",
+	})
+
+	lister := newFakeClusterLister(t, nil, object)
+
+	nodes := visitWithResourceVisitor(t, lister, NewFluxVisitor(lister), []*unstructured.Unstructured{object})
+
+	node, ok := nodes[string(object.GetUID())]
+	if !ok {
+		t.Fatalf("node was not emitted")
+	}
+
+	if got := nodeParent(node); got != "" {
+		t.Errorf("parent = %q, want none", got)
+	}
+}
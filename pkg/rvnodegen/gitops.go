@@ -0,0 +1,84 @@
+package rvnodegen
+
+import (
+	"errors"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/utils/pointer"
+)
+
+const (
+	// SyncStatusSynced is a GitOps object whose live state matches its source.
+	SyncStatusSynced = "Synced"
+	// SyncStatusOutOfSync is a GitOps object whose live state does not match its source.
+	SyncStatusOutOfSync = "OutOfSync"
+	// SyncStatusUnknown is a GitOps object whose sync status can't be determined.
+	SyncStatusUnknown = "Unknown"
+)
+
+// isMissing returns true if an error means an object does not exist.
+func isMissing(err error) bool {
+	return kerrors.IsNotFound(err)
+}
+
+// isUnknownResource returns true if an error means an object's resource type is not served by
+// the cluster or can't be read.
+func isUnknownResource(err error) bool {
+	return errors.Is(err, ErrUnknownResource)
+}
+
+// isUnavailable returns true if an error means an object does not exist or its resource type
+// is unknown.
+func isUnavailable(err error) bool {
+	return isMissing(err) || isUnknownResource(err)
+}
+
+// condition is a status condition.
+type condition struct {
+	Status  string
+	Reason  string
+	Message string
+}
+
+// findCondition finds a status condition by type.
+func findCondition(object *unstructured.Unstructured, conditionType string) (condition, bool, error) {
+	conditions, _, err := unstructured.NestedSlice(object.Object, "status", "conditions")
+	if err != nil {
+		return condition{}, false, err
+	}
+
+	for i := range conditions {
+		m, ok := conditions[i].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		if t, _, _ := unstructured.NestedString(m, "type"); t != conditionType {
+			continue
+		}
+
+		c := condition{}
+		c.Status, _, _ = unstructured.NestedString(m, "status")
+		c.Reason, _, _ = unstructured.NestedString(m, "reason")
+		c.Message, _, _ = unstructured.NestedString(m, "message")
+
+		return c, true, nil
+	}
+
+	return condition{}, false, nil
+}
+
+// setGitOpsParent places a node in the group of the GitOps object that manages it. Nodes
+// already in a group keep their parent.
+func setGitOpsParent(controller *unstructured.Unstructured, node GraphNode, visitor *Visitor) (GraphNode, error) {
+	if node.Parent == nil {
+		node.Parent = pointer.StringPtr(string(controller.GetUID()))
+	}
+
+	if err := visitor.Visit(true, controller); err != nil {
+		return GraphNode{}, err
+	}
+
+	return node, nil
+}
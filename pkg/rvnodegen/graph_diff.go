@@ -0,0 +1,194 @@
+package rvnodegen
+
+import (
+	"reflect"
+	"sort"
+)
+
+// GraphDiff is the difference between two graphs.
+type GraphDiff struct {
+	// Added are nodes that only exist in the second graph.
+	Added []GraphNode `json:"added"`
+	// Removed are nodes that only exist in the first graph.
+	Removed []GraphNode `json:"removed"`
+	// Changed are nodes that exist in both graphs with different fields.
+	Changed []NodeChange `json:"changed"`
+	// AddedEdges are edges that only exist in the second graph.
+	AddedEdges []Edge `json:"addedEdges"`
+	// RemovedEdges are edges that only exist in the first graph.
+	RemovedEdges []Edge `json:"removedEdges"`
+}
+
+// IsEmpty returns true if the graphs are the same.
+func (d GraphDiff) IsEmpty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0 &&
+		len(d.AddedEdges) == 0 && len(d.RemovedEdges) == 0
+}
+
+// NodeChange describes the changed fields of a node.
+type NodeChange struct {
+	// ID is the node's id.
+	ID string `json:"id"`
+	// Label is the node's label.
+	Label string `json:"label"`
+	// Fields are the changed fields.
+	Fields []FieldChange `json:"fields"`
+}
+
+// FieldChange is a changed node field.
+type FieldChange struct {
+	// Field is the name of the field.
+	Field string `json:"field"`
+	// Before is the field value in the first graph.
+	Before interface{} `json:"before"`
+	// After is the field value in the second graph.
+	After interface{} `json:"after"`
+}
+
+// DiffGraphs compares two graphs. Nodes are matched by id.
+func DiffGraphs(before, after []GraphNode) GraphDiff {
+	diff := GraphDiff{
+		Added:        []GraphNode{},
+		Removed:      []GraphNode{},
+		Changed:      []NodeChange{},
+		AddedEdges:   []Edge{},
+		RemovedEdges: []Edge{},
+	}
+
+	beforeByID := nodesByID(before)
+	afterByID := nodesByID(after)
+
+	for _, node := range after {
+		previous, ok := beforeByID[node.ID]
+		if !ok {
+			diff.Added = append(diff.Added, node)
+			continue
+		}
+
+		if fields := diffNode(previous, node); len(fields) > 0 {
+			diff.Changed = append(diff.Changed, NodeChange{
+				ID:     node.ID,
+				Label:  node.Label,
+				Fields: fields,
+			})
+		}
+	}
+
+	for _, node := range before {
+		if _, ok := afterByID[node.ID]; !ok {
+			diff.Removed = append(diff.Removed, node)
+		}
+	}
+
+	beforeEdges := edgeSet(before)
+	afterEdges := edgeSet(after)
+
+	for key, edge := range afterEdges {
+		if _, ok := beforeEdges[key]; !ok {
+			diff.AddedEdges = append(diff.AddedEdges, edge)
+		}
+	}
+
+	for key, edge := range beforeEdges {
+		if _, ok := afterEdges[key]; !ok {
+			diff.RemovedEdges = append(diff.RemovedEdges, edge)
+		}
+	}
+
+	sortEdges(diff.AddedEdges)
+	sortEdges(diff.RemovedEdges)
+
+	return diff
+}
+
+func diffNode(before, after GraphNode) []FieldChange {
+	var fields []FieldChange
+
+	add := func(field string, b, a interface{}) {
+		if !reflect.DeepEqual(b, a) {
+			fields = append(fields, FieldChange{Field: field, Before: b, After: a})
+		}
+	}
+
+	add("label", before.Label, after.Label)
+	add("healthStatus", before.HealthStatus, after.HealthStatus)
+	add("parent", stringValue(before.Parent), stringValue(after.Parent))
+	add("targets", sortedStrings(before.Targets), sortedStrings(after.Targets))
+
+	var beforeLabels, afterLabels map[string]string
+	var beforeStatus, afterStatus string
+	if before.Metadata != nil {
+		beforeLabels = before.Metadata.Labels
+		beforeStatus = before.Metadata.Status
+	}
+	if after.Metadata != nil {
+		afterLabels = after.Metadata.Labels
+		afterStatus = after.Metadata.Status
+	}
+
+	if len(beforeLabels) > 0 || len(afterLabels) > 0 {
+		add("labels", beforeLabels, afterLabels)
+	}
+	add("status", beforeStatus, afterStatus)
+
+	return fields
+}
+
+func nodesByID(nodes []GraphNode) map[string]GraphNode {
+	m := map[string]GraphNode{}
+	for _, node := range nodes {
+		m[node.ID] = node
+	}
+	return m
+}
+
+// nodeEdges returns a node's edges. Nodes without typed edges have edges created from targets.
+func nodeEdges(node GraphNode) []Edge {
+	if len(node.Edges) > 0 || len(node.Targets) == 0 {
+		return node.Edges
+	}
+
+	var edges []Edge
+	for _, target := range node.Targets {
+		edges = append(edges, Edge{Source: node.ID, Target: target})
+	}
+
+	return edges
+}
+
+func edgeSet(nodes []GraphNode) map[string]Edge {
+	m := map[string]Edge{}
+	for _, node := range nodes {
+		for _, edge := range nodeEdges(node) {
+			m[edgeKey(edge)] = edge
+		}
+	}
+	return m
+}
+
+func edgeKey(edge Edge) string {
+	return edge.Source + "|" + edge.Target + "|" + string(edge.Relation)
+}
+
+func sortEdges(edges []Edge) {
+	sort.Slice(edges, func(i, j int) bool {
+		return edgeKey(edges[i]) < edgeKey(edges[j])
+	})
+}
+
+func sortedStrings(in []string) []string {
+	if len(in) == 0 {
+		return nil
+	}
+
+	out := append([]string(nil), in...)
+	sort.Strings(out)
+	return out
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
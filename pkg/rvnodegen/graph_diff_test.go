@@ -0,0 +1,102 @@
+package rvnodegen
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func buildTestGraph(t *testing.T, objects ...*unstructured.Unstructured) []GraphNode {
+	t.Helper()
+
+	lister := newFakeClusterLister(t, fakeWorkloadResources, objects...)
+
+	nodes, err := NewNodeBuilder(lister).Build("default")
+	if err != nil {
+		t.Fatalf("build graph: %v", err)
+	}
+
+	return nodes
+}
+
+func TestDiffGraphs(t *testing.T) {
+	deployment, replicaSet, pod := newTestDeployment("default", "web", "config-a")
+	before := buildTestGraph(t, deployment, replicaSet, pod,
+		newTestObject(configMapGVK, "default", "config-a"))
+
+	deployment, replicaSet, pod = newTestDeployment("default", "web", "config-b")
+	deployment.SetLabels(map[string]string{"team": "payments"})
+	after := buildTestGraph(t, deployment, replicaSet, pod,
+		newTestObject(configMapGVK, "default", "config-b"))
+
+	diff := DiffGraphs(before, after)
+
+	nodeIDs := func(nodes []GraphNode) []string {
+		var ids []string
+		for _, node := range nodes {
+			ids = append(ids, node.ID)
+		}
+		return ids
+	}
+
+	if got, want := nodeIDs(diff.Added), []string{"ConfigMap/default/config-b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("added = %v, want %v", got, want)
+	}
+	if got, want := nodeIDs(diff.Removed), []string{"ConfigMap/default/config-a"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("removed = %v, want %v", got, want)
+	}
+
+	edgeKeys := func(edges []Edge) []string {
+		var keys []string
+		for _, edge := range edges {
+			keys = append(keys, edgeKey(edge))
+		}
+		return keys
+	}
+
+	rsID := string(replicaSet.GetUID())
+	if got, want := edgeKeys(diff.AddedEdges), []string{rsID + "|ConfigMap/default/config-b|volume"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("added edges = %v, want %v", got, want)
+	}
+	if got, want := edgeKeys(diff.RemovedEdges), []string{rsID + "|ConfigMap/default/config-a|volume"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("removed edges = %v, want %v", got, want)
+	}
+
+	changed := map[string][]string{}
+	for _, change := range diff.Changed {
+		for _, field := range change.Fields {
+			changed[change.ID] = append(changed[change.ID], field.Field)
+		}
+	}
+
+	want := map[string][]string{
+		string(deployment.GetUID()): {"labels"},
+		rsID:                        {"targets"},
+	}
+	if !reflect.DeepEqual(changed, want) {
+		t.Errorf("changed = %v, want %v", changed, want)
+	}
+
+	if !DiffGraphs(before, before).IsEmpty() {
+		t.Error("diff of a graph with itself is not empty")
+	}
+}
+
+func TestDiffGraphs_targets(t *testing.T) {
+	// nodes without typed edges are compared by their targets.
+	before := []GraphNode{{ID: "a", Targets: []string{"b"}}, {ID: "b"}}
+	after := []GraphNode{{ID: "a", Targets: []string{"c"}}, {ID: "b"}, {ID: "c"}}
+
+	diff := DiffGraphs(before, after)
+
+	if got, want := diff.AddedEdges, []Edge{{Source: "a", Target: "c"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("added edges = %v, want %v", got, want)
+	}
+	if got, want := diff.RemovedEdges, []Edge{{Source: "a", Target: "b"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("removed edges = %v, want %v", got, want)
+	}
+	if len(diff.Changed) != 1 || diff.Changed[0].Fields[0].Field != "targets" {
+		t.Errorf("changed = %+v, want a targets change for a", diff.Changed)
+	}
+}
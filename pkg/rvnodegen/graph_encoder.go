@@ -0,0 +1,230 @@
+package rvnodegen
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/json"
+)
+
+// GraphEncoder encodes graph nodes in a format.
+type GraphEncoder interface {
+	// Format is the name of the format used with the format query parameter.
+	Format() string
+	// ContentType is the media type of the encoding.
+	ContentType() string
+	// Encode writes the nodes to w.
+	Encode(w io.Writer, nodes []GraphNode) error
+}
+
+// graphEncoders are the graph encoders available for responses. The first encoder is the
+// default.
+var graphEncoders = []GraphEncoder{
+	&JSONGraphEncoder{},
+	&DOTGraphEncoder{},
+	&MermaidGraphEncoder{},
+	&CytoscapeGraphEncoder{},
+	&GraphMLGraphEncoder{},
+	&SVGGraphEncoder{},
+}
+
+// metadataGraphEncoder is implemented by encoders that need node metadata fields.
+type metadataGraphEncoder interface {
+	// MetadataFields are the metadata fields the encoder uses.
+	MetadataFields() []MetadataField
+}
+
+// edgeGraphEncoder is implemented by encoders that draw typed edges. They are given version 2
+// nodes whatever the requested response version, so edges keep their relations.
+type edgeGraphEncoder interface {
+	// TypedEdges returns true if the encoder draws typed edges.
+	TypedEdges() bool
+}
+
+// GraphEncoderForFormat returns the encoder for a format, e.g. "json", "dot" or "mermaid".
+func GraphEncoderForFormat(format string) (GraphEncoder, error) {
+	for _, encoder := range graphEncoders {
+		if encoder.Format() == format {
+			return encoder, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown format %q", format)
+}
+
+// forEncoder returns response options that include the metadata fields and edges an encoder
+// needs.
+func (ro ResponseOptions) forEncoder(encoder GraphEncoder) ResponseOptions {
+	if ee, ok := encoder.(edgeGraphEncoder); ok && ee.TypedEdges() {
+		ro.Version = ResponseVersionV2
+	}
+
+	me, ok := encoder.(metadataGraphEncoder)
+	if !ok {
+		return ro
+	}
+
+	fields := append([]MetadataField(nil), ro.MetadataFields...)
+	for _, field := range me.MetadataFields() {
+		if !metadataFieldsInclude(fields, field) {
+			fields = append(fields, field)
+		}
+	}
+	ro.MetadataFields = fields
+
+	return ro
+}
+
+func metadataFieldsInclude(fields []MetadataField, field MetadataField) bool {
+	for i := range fields {
+		if fields[i] == field {
+			return true
+		}
+	}
+	return false
+}
+
+// graphEncoderForRequest selects an encoder using the format query parameter or the Accept
+// header. JSON is used if neither selects an encoder.
+func graphEncoderForRequest(r *http.Request) (GraphEncoder, error) {
+	if format := r.URL.Query().Get("format"); format != "" {
+		return GraphEncoderForFormat(format)
+	}
+
+	for _, accept := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(accept))
+		if err != nil {
+			continue
+		}
+
+		for _, encoder := range graphEncoders {
+			if encoder.ContentType() == mediaType {
+				return encoder, nil
+			}
+		}
+	}
+
+	return graphEncoders[0], nil
+}
+
+// respondWithGraph writes nodes to a response with an encoder.
+func respondWithGraph(w http.ResponseWriter, encoder GraphEncoder, nodes []GraphNode) {
+	w.Header().Set("Content-Type", encoder.ContentType())
+	w.WriteHeader(http.StatusOK)
+	_ = encoder.Encode(w, nodes)
+}
+
+// JSONGraphEncoder encodes nodes as JSON.
+type JSONGraphEncoder struct{}
+
+var _ GraphEncoder = &JSONGraphEncoder{}
+
+// Format is the name of the format.
+func (e *JSONGraphEncoder) Format() string {
+	return "json"
+}
+
+// ContentType is the media type of the encoding.
+func (e *JSONGraphEncoder) ContentType() string {
+	return "application/json"
+}
+
+// Encode writes the nodes to w.
+func (e *JSONGraphEncoder) Encode(w io.Writer, nodes []GraphNode) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(nodesResponse{Nodes: nodes})
+}
+
+// healthColors are the stroke and fill colors for a health status.
+type healthColors struct {
+	stroke string
+	fill   string
+}
+
+func colorsForHealth(status HealthStatusType) healthColors {
+	switch status {
+	case HealthStatusTypeHealthy:
+		return healthColors{stroke: "#2e7d32", fill: "#c8e6c9"}
+	case HealthStatusTypeDegraded:
+		return healthColors{stroke: "#f9a825", fill: "#fff9c4"}
+	case HealthStatusTypeFailure:
+		return healthColors{stroke: "#c62828", fill: "#ffcdd2"}
+	case HealthStatusTypeMissing:
+		return healthColors{stroke: "#6a1b9a", fill: "#f3e5f5"}
+	default:
+		return healthColors{stroke: "#757575", fill: "#eeeeee"}
+	}
+}
+
+// graphTree indexes nodes by their parent. Nodes whose parent is not in the graph are
+// roots.
+type graphTree struct {
+	byID     map[string]GraphNode
+	roots    []GraphNode
+	children map[string][]GraphNode
+}
+
+func newGraphTree(nodes []GraphNode) graphTree {
+	t := graphTree{
+		byID:     nodesByID(nodes),
+		children: map[string][]GraphNode{},
+	}
+
+	for _, node := range nodes {
+		if node.Parent != nil {
+			if _, ok := t.byID[*node.Parent]; ok {
+				t.children[*node.Parent] = append(t.children[*node.Parent], node)
+				continue
+			}
+		}
+		t.roots = append(t.roots, node)
+	}
+
+	return t
+}
+
+// graphNodeAttributes returns the kind, namespace, health and node type of a node. Empty values
+// are omitted.
+func graphNodeAttributes(node GraphNode) map[string]string {
+	attributes := map[string]string{}
+
+	if node.Metadata != nil {
+		if node.Metadata.Kind != "" {
+			attributes["kind"] = node.Metadata.Kind
+		}
+		if node.Metadata.Namespace != "" {
+			attributes["namespace"] = node.Metadata.Namespace
+		}
+	}
+
+	if node.HealthStatus != "" {
+		attributes["health"] = string(node.HealthStatus)
+	}
+
+	if node.NodeType != "" {
+		attributes["nodeType"] = string(node.NodeType)
+	}
+
+	return attributes
+}
+
+// graphEdges returns the edges between nodes in the graph, sorted for stable output.
+func graphEdges(nodes []GraphNode) []Edge {
+	byID := nodesByID(nodes)
+
+	var edges []Edge
+	for _, node := range nodes {
+		for _, edge := range nodeEdges(node) {
+			if _, ok := byID[edge.Target]; ok {
+				edges = append(edges, edge)
+			}
+		}
+	}
+
+	sortEdges(edges)
+
+	return edges
+}
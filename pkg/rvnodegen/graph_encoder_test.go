@@ -0,0 +1,34 @@
+package rvnodegen
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestResponseOptions_forEncoder(t *testing.T) {
+	pod := GraphNode{ID: "pod", Label: "web", Metadata: &NodeMetadata{Kind: "Pod", Namespace: "default", Name: "web"}}
+	pod = addEdge(pod, "config", EdgeRelationVolume, nil, EdgeProvenance{})
+	nodes := []GraphNode{
+		pod,
+		{ID: "config", Label: "config", Metadata: &NodeMetadata{Kind: "ConfigMap", Namespace: "default", Name: "config"}},
+	}
+
+	// version 1 is the default response version.
+	ro := ResponseOptions{Version: ResponseVersionV1}
+
+	for _, encoder := range graphEncoders {
+		t.Run(encoder.Format(), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := encoder.Encode(&buf, ro.forEncoder(encoder).Apply(nodes)); err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+
+			// the json encoder keeps the requested version, which only has targets.
+			want := encoder.Format() != "json"
+			if got := strings.Contains(buf.String(), string(EdgeRelationVolume)); got != want {
+				t.Errorf("output has relation = %t, want %t:\n%s", got, want, buf.String())
+			}
+		})
+	}
+}
@@ -34,6 +34,18 @@ type GraphNode struct {
 
 	// Targets are ids this node points to.
 	Targets []string `json:"targets,omitempty"`
+
+	// Edges are the typed edges from this node.
+	Edges []Edge `json:"edges,omitempty"`
+
+	// Metadata is metadata for the object the node represents. It is optional.
+	Metadata *NodeMetadata `json:"metadata,omitempty"`
+
+	// Extra is additional information about the node. It is optional.
+	Extra map[string]interface{} `json:"extra,omitempty"`
+
+	// Layout is the node's position and size when a server side layout is requested.
+	Layout *NodeLayout `json:"layout,omitempty"`
 }
 
 // NodeType is the type of node.
@@ -48,6 +60,18 @@ const (
 	NodeTypeConfiguration NodeType = "configuration"
 	// NodeTypeCustomResource is a custom resource node
 	NodeTypeCustomResource NodeType = "custom-resource"
+	// NodeTypeApplication is a synthetic application group node.
+	NodeTypeApplication NodeType = "application"
+	// NodeTypeHelmRelease is a synthetic Helm release group node.
+	NodeTypeHelmRelease NodeType = "helm-release"
+	// NodeTypeGitOps is a GitOps controller node.
+	NodeTypeGitOps NodeType = "gitops"
+	// NodeTypeStorage is a storage node.
+	NodeTypeStorage NodeType = "storage"
+	// NodeTypeInfrastructure is a cluster infrastructure node.
+	NodeTypeInfrastructure NodeType = "infrastructure"
+	// NodeTypeNamespace is a synthetic namespace group node.
+	NodeTypeNamespace NodeType = "namespace"
 )
 
 func detectNodeType(lister Lister, object runtime.Object) (NodeType, error) {
@@ -66,14 +90,23 @@ func detectNodeType(lister Lister, object runtime.Object) (NodeType, error) {
 		return NodeTypeWorkload, nil
 	}
 
-	if isGroupKindMatch(groupKind, []schema.GroupVersionKind{ingressGVK, serviceGVK}) {
+	if isGroupKindMatch(groupKind, []schema.GroupVersionKind{ingressGVK, ingressClassGVK, serviceGVK}) {
 		return NodeTypeNetworking, nil
 	}
 
-	if isGroupKindMatch(groupKind, []schema.GroupVersionKind{configMapGVK, secretGVK, serviceAccountGVK}) {
+	if isGroupKindMatch(groupKind, []schema.GroupVersionKind{clusterRoleGVK, clusterRoleBindingGVK, configMapGVK,
+		roleGVK, roleBindingGVK, secretGVK, serviceAccountGVK}) {
 		return NodeTypeConfiguration, nil
 	}
 
+	if isGroupKindMatch(groupKind, []schema.GroupVersionKind{persistentVolumeGVK, persistentVolumeClaimGVK, storageClassGVK}) {
+		return NodeTypeStorage, nil
+	}
+
+	if isGroupKindMatch(groupKind, []schema.GroupVersionKind{nodeGVK}) {
+		return NodeTypeInfrastructure, nil
+	}
+
 	customResourceDefinitions, err := lister.List(crdGVK, labels.Everything())
 	if err != nil {
 		return "", fmt.Errorf("list custom resource definitions: %w", err)
@@ -0,0 +1,98 @@
+package rvnodegen
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultGraphSnapshotLimit is the number of graph snapshots kept by default.
+const DefaultGraphSnapshotLimit = 100
+
+// GraphSnapshot is a graph saved at a point in time.
+type GraphSnapshot struct {
+	// ID is the snapshot's id.
+	ID string `json:"id"`
+	// CreatedAt is when the snapshot was taken.
+	CreatedAt time.Time `json:"createdAt"`
+	// Scope is the scope the graph was built for.
+	Scope Scope `json:"scope"`
+	// Nodes are the graph's nodes.
+	Nodes []GraphNode `json:"nodes,omitempty"`
+}
+
+// GraphSnapshotStore stores graph snapshots in memory. When the store is full, the oldest
+// snapshot is removed.
+type GraphSnapshotStore struct {
+	mu        sync.Mutex
+	limit     int
+	snapshots map[string]GraphSnapshot
+}
+
+// NewGraphSnapshotStore creates an instance of GraphSnapshotStore.
+func NewGraphSnapshotStore(limit int) *GraphSnapshotStore {
+	s := &GraphSnapshotStore{
+		limit:     limit,
+		snapshots: map[string]GraphSnapshot{},
+	}
+	return s
+}
+
+// Add adds a snapshot for a graph.
+func (s *GraphSnapshotStore) Add(scope Scope, nodes []GraphNode) GraphSnapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot := GraphSnapshot{
+		ID:        uuid.New().String(),
+		CreatedAt: time.Now(),
+		Scope:     scope,
+		Nodes:     nodes,
+	}
+
+	for len(s.snapshots) >= s.limit && len(s.snapshots) > 0 {
+		oldest := s.list()[0]
+		delete(s.snapshots, oldest.ID)
+	}
+
+	s.snapshots[snapshot.ID] = snapshot
+
+	return snapshot
+}
+
+// Get gets a snapshot by id.
+func (s *GraphSnapshotStore) Get(id string) (GraphSnapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot, ok := s.snapshots[id]
+	if !ok {
+		return GraphSnapshot{}, fmt.Errorf("graph snapshot %q not found", id)
+	}
+
+	return snapshot, nil
+}
+
+// List lists snapshots, oldest first.
+func (s *GraphSnapshotStore) List() []GraphSnapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.list()
+}
+
+func (s *GraphSnapshotStore) list() []GraphSnapshot {
+	var list []GraphSnapshot
+	for _, snapshot := range s.snapshots {
+		list = append(list, snapshot)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].CreatedAt.Before(list[j].CreatedAt)
+	})
+
+	return list
+}
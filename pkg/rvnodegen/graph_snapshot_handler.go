@@ -0,0 +1,108 @@
+package rvnodegen
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+type graphSnapshotsResponse struct {
+	Snapshots []GraphSnapshot `json:"snapshots"`
+}
+
+type graphDiffResponse struct {
+	Snapshot GraphSnapshot `json:"snapshot"`
+	Diff     GraphDiff     `json:"diff"`
+}
+
+// GraphSnapshotHandler is a HTTP handler for creating and listing graph snapshots.
+type GraphSnapshotHandler struct {
+	lister  Lister
+	store   *GraphSnapshotStore
+	options []Option
+}
+
+var _ http.Handler = &GraphSnapshotHandler{}
+
+// NewGraphSnapshotHandler creates an instance of GraphSnapshotHandler.
+func NewGraphSnapshotHandler(lister Lister, store *GraphSnapshotStore, options ...Option) *GraphSnapshotHandler {
+	h := &GraphSnapshotHandler{
+		lister:  lister,
+		store:   store,
+		options: options,
+	}
+	return h
+}
+
+// ServeHTTP serves the handler. POST snapshots the live graph for the requested scope, and GET
+// lists snapshots without their nodes.
+func (h *GraphSnapshotHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		var snapshots []GraphSnapshot
+		for _, snapshot := range h.store.List() {
+			snapshot.Nodes = nil
+			snapshots = append(snapshots, snapshot)
+		}
+
+		respondWithJSON(w, graphSnapshotsResponse{Snapshots: snapshots}, http.StatusOK)
+		return
+	}
+
+	scope, err := scopeFromQuery(r.URL.Query())
+	if err != nil {
+		respondWithError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	nb := NewNodeBuilder(h.lister, h.options...)
+	nodes, err := nb.BuildScope(scope)
+	if err != nil {
+		respondWithError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	snapshot := h.store.Add(scope, nodes)
+	snapshot.Nodes = nil
+
+	respondWithJSON(w, snapshot, http.StatusCreated)
+}
+
+// GraphDiffHandler is a HTTP handler that compares the live graph with a graph snapshot.
+type GraphDiffHandler struct {
+	lister  Lister
+	store   *GraphSnapshotStore
+	options []Option
+}
+
+var _ http.Handler = &GraphDiffHandler{}
+
+// NewGraphDiffHandler creates an instance of GraphDiffHandler.
+func NewGraphDiffHandler(lister Lister, store *GraphSnapshotStore, options ...Option) *GraphDiffHandler {
+	h := &GraphDiffHandler{
+		lister:  lister,
+		store:   store,
+		options: options,
+	}
+	return h
+}
+
+// ServeHTTP serves the handler. The live graph is built with the snapshot's scope.
+func (h *GraphDiffHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	snapshot, err := h.store.Get(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, err, http.StatusNotFound)
+		return
+	}
+
+	nb := NewNodeBuilder(h.lister, h.options...)
+	nodes, err := nb.BuildScope(snapshot.Scope)
+	if err != nil {
+		respondWithError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	diff := DiffGraphs(snapshot.Nodes, nodes)
+	snapshot.Nodes = nil
+
+	respondWithJSON(w, graphDiffResponse{Snapshot: snapshot, Diff: diff}, http.StatusOK)
+}
@@ -0,0 +1,147 @@
+package rvnodegen
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+)
+
+const graphMLNamespace = "http://graphml.graphdrawing.org/xmlns"
+
+// graphMLKeys are the typed attributes of GraphML nodes and edges.
+var graphMLKeys = []graphMLKey{
+	{ID: "label", For: "node", Name: "label", Type: "string"},
+	{ID: "kind", For: "node", Name: "kind", Type: "string"},
+	{ID: "namespace", For: "node", Name: "namespace", Type: "string"},
+	{ID: "health", For: "node", Name: "health", Type: "string"},
+	{ID: "nodeType", For: "node", Name: "nodeType", Type: "string"},
+	{ID: "group", For: "node", Name: "group", Type: "boolean"},
+	{ID: "parent", For: "node", Name: "parent", Type: "string"},
+	{ID: "relation", For: "edge", Name: "relation", Type: "string"},
+}
+
+// GraphMLGraphEncoder encodes nodes as a GraphML directed graph. Node kind, namespace, health,
+// node type and parent, and edge relations are typed attributes.
+type GraphMLGraphEncoder struct{}
+
+var _ GraphEncoder = &GraphMLGraphEncoder{}
+
+type graphMLDocument struct {
+	XMLName xml.Name     `xml:"graphml"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   graphMLGraph `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID   string `xml:"id,attr"`
+	For  string `xml:"for,attr"`
+	Name string `xml:"attr.name,attr"`
+	Type string `xml:"attr.type,attr"`
+}
+
+type graphMLGraph struct {
+	ID          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	ID     string        `xml:"id,attr"`
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+// Format is the name of the format.
+func (e *GraphMLGraphEncoder) Format() string {
+	return "graphml"
+}
+
+// ContentType is the media type of the encoding.
+func (e *GraphMLGraphEncoder) ContentType() string {
+	return "application/graphml+xml"
+}
+
+// TypedEdges returns true because edges are labeled with their relation.
+func (e *GraphMLGraphEncoder) TypedEdges() bool {
+	return true
+}
+
+// MetadataFields are the metadata fields included as node attributes.
+func (e *GraphMLGraphEncoder) MetadataFields() []MetadataField {
+	return []MetadataField{MetadataFieldKind, MetadataFieldNamespace}
+}
+
+// Encode writes the nodes to w.
+func (e *GraphMLGraphEncoder) Encode(w io.Writer, nodes []GraphNode) error {
+	doc := graphMLDocument{
+		XMLNS: graphMLNamespace,
+		Keys:  graphMLKeys,
+		Graph: graphMLGraph{
+			ID:          "G",
+			EdgeDefault: "directed",
+		},
+	}
+
+	byID := nodesByID(nodes)
+
+	for _, node := range nodes {
+		attributes := graphNodeAttributes(node)
+
+		data := []graphMLData{{Key: "label", Value: node.Label}}
+		for _, key := range []string{"kind", "namespace", "health", "nodeType"} {
+			if value, ok := attributes[key]; ok {
+				data = append(data, graphMLData{Key: key, Value: value})
+			}
+		}
+
+		data = append(data, graphMLData{Key: "group", Value: strconv.FormatBool(node.IsGroup != nil)})
+
+		if node.Parent != nil {
+			if _, ok := byID[*node.Parent]; ok {
+				data = append(data, graphMLData{Key: "parent", Value: *node.Parent})
+			}
+		}
+
+		doc.Graph.Nodes = append(doc.Graph.Nodes, graphMLNode{ID: node.ID, Data: data})
+	}
+
+	for _, edge := range graphEdges(nodes) {
+		element := graphMLEdge{
+			ID:     edgeKey(edge),
+			Source: edge.Source,
+			Target: edge.Target,
+		}
+
+		if edge.Relation != "" {
+			element.Data = []graphMLData{{Key: "relation", Value: string(edge.Relation)}}
+		}
+
+		doc.Graph.Edges = append(doc.Graph.Edges, element)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,44 @@
+package rvnodegen
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// GrouperFactory is a factory that creates Groupers.
+type GrouperFactory func(lister Lister) (Grouper, error)
+
+// Grouper is an interface for grouping graph nodes after objects have been visited.
+type Grouper interface {
+	// Name is the name of the grouper.
+	Name() string
+	// Group groups nodes. Objects are the visited objects keyed by node id. Nodes that are
+	// synthesized by a grouper do not have an object.
+	Group(nodes []GraphNode, objects map[string]*unstructured.Unstructured) ([]GraphNode, error)
+}
+
+var healthStatusRank = map[HealthStatusType]int{
+	HealthStatusTypeHealthy:  1,
+	HealthStatusTypeDegraded: 2,
+	HealthStatusTypeFailure:  3,
+	HealthStatusTypeMissing:  3,
+}
+
+// worseHealthStatus returns the worse of two health statuses.
+func worseHealthStatus(a, b HealthStatusType) HealthStatusType {
+	if healthStatusRank[b] > healthStatusRank[a] {
+		return b
+	}
+
+	return a
+}
+
+// groupableObject returns the object for a node if the node can be placed in a new group. Nodes
+// already in a group or synthesized by a grouper can't be grouped.
+func groupableObject(node GraphNode, objects map[string]*unstructured.Unstructured) (*unstructured.Unstructured, bool) {
+	if node.Parent != nil {
+		return nil, false
+	}
+
+	object, ok := objects[node.ID]
+	return object, ok
+}
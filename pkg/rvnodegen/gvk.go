@@ -1,25 +1,49 @@
 package rvnodegen
 
 import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 var (
+	argoApplicationGVK       = schema.GroupVersionKind{Group: "argoproj.io", Version: "v1alpha1", Kind: "Application"}
+	clusterRoleBindingGVK    = schema.GroupVersionKind{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "ClusterRoleBinding"}
+	clusterRoleGVK           = schema.GroupVersionKind{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "ClusterRole"}
 	configMapGVK             = schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}
 	crdGVK                   = schema.GroupVersionKind{Group: "apiextensions.k8s.io", Version: "v1", Kind: "CustomResourceDefinition"}
 	cronJobGVK               = schema.GroupVersionKind{Group: "batch", Version: "v1beta1", Kind: "CronJob"}
 	daemonSetGVK             = schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "DaemonSet"}
 	deploymentGVK            = schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}
+	endpointsGVK             = schema.GroupVersionKind{Version: "v1", Kind: "Endpoints"}
+	fluxHelmReleaseGVK       = schema.GroupVersionKind{Group: "helm.toolkit.fluxcd.io", Version: "v2beta1", Kind: "HelmRelease"}
+	fluxKustomizationGVK     = schema.GroupVersionKind{Group: "kustomize.toolkit.fluxcd.io", Version: "v1beta1", Kind: "Kustomization"}
 	ingressGVK               = schema.GroupVersionKind{Group: "networking.k8s.io", Version: "v1", Kind: "Ingress"}
+	ingressClassGVK          = schema.GroupVersionKind{Group: "networking.k8s.io", Version: "v1", Kind: "IngressClass"}
 	jobGVK                   = schema.GroupVersionKind{Group: "batch", Version: "v1", Kind: "Job"}
+	namespaceGVK             = schema.GroupVersionKind{Version: "v1", Kind: "Namespace"}
+	nodeGVK                  = schema.GroupVersionKind{Version: "v1", Kind: "Node"}
+	persistentVolumeClaimGVK = schema.GroupVersionKind{Version: "v1", Kind: "PersistentVolumeClaim"}
+	persistentVolumeGVK      = schema.GroupVersionKind{Version: "v1", Kind: "PersistentVolume"}
 	podGVK                   = schema.GroupVersionKind{Version: "v1", Kind: "Pod"}
 	replicaSetGVK            = schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "ReplicaSet"}
+	roleBindingGVK           = schema.GroupVersionKind{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "RoleBinding"}
+	roleGVK                  = schema.GroupVersionKind{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "Role"}
 	replicationControllerGVK = schema.GroupVersionKind{Version: "v1", Kind: "ReplicationController"}
 	secretGVK                = schema.GroupVersionKind{Version: "v1", Kind: "Secret"}
 	serviceAccountGVK        = schema.GroupVersionKind{Version: "v1", Kind: "ServiceAccount"}
 	serviceGVK               = schema.GroupVersionKind{Version: "v1", Kind: "Service"}
 	statefulSetGVK           = schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "StatefulSet"}
+	storageClassGVK          = schema.GroupVersionKind{Group: "storage.k8s.io", Version: "v1", Kind: "StorageClass"}
+
+	// clusterScopedGVKs are the cluster scoped kinds included in multi namespace builds.
+	clusterScopedGVKs = []schema.GroupVersionKind{clusterRoleGVK, clusterRoleBindingGVK, persistentVolumeGVK,
+		storageClassGVK, ingressClassGVK, nodeGVK}
+
+	// gitOpsGVKs are the kinds of GitOps objects that manage other objects.
+	gitOpsGVKs = []schema.GroupVersionKind{argoApplicationGVK, fluxKustomizationGVK, fluxHelmReleaseGVK}
 )
 
 func isPod(object *unstructured.Unstructured) bool {
@@ -47,3 +71,13 @@ func isGroupKindMatch(groupKind schema.GroupKind, list []schema.GroupVersionKind
 
 	return false
 }
+
+// ownerGVK returns the group/version/kind of an owner reference.
+func ownerGVK(ref metav1.OwnerReference) (schema.GroupVersionKind, error) {
+	gv, err := schema.ParseGroupVersion(ref.APIVersion)
+	if err != nil {
+		return schema.GroupVersionKind{}, fmt.Errorf("parse API version %q: %w", ref.APIVersion, err)
+	}
+
+	return gv.WithKind(ref.Kind), nil
+}
@@ -1,6 +1,9 @@
 package rvnodegen
 
 import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -14,6 +17,11 @@ const (
 	HealthStatusTypeDegraded HealthStatusType = "Degraded"
 	// HealthStatusTypeFailure is a failed object.
 	HealthStatusTypeFailure HealthStatusType = "Failure"
+	// HealthStatusTypeMissing is a referenced object that does not exist.
+	HealthStatusTypeMissing HealthStatusType = "Missing"
+	// HealthStatusTypeUnknown is a referenced object whose kind is not served by the cluster or
+	// can't be read.
+	HealthStatusTypeUnknown HealthStatusType = "Unknown"
 )
 
 // HealthStatuserFactory is a factory that creates HealthStatusers.
@@ -40,7 +48,18 @@ func NewClusterHealthStatus(lister Lister) *ClusterHealthStatus {
 	return hs
 }
 
-// HealthStatus generates status for an object.
+// HealthStatus generates status for an object. Pods are checked using their status, and other
+// objects are healthy.
 func (hs *ClusterHealthStatus) HealthStatus(object runtime.Object) (HealthStatusType, error) {
-	return HealthStatusTypeHealthy, nil
+	u, ok := object.(*unstructured.Unstructured)
+	if !ok || !isPod(u) {
+		return HealthStatusTypeHealthy, nil
+	}
+
+	status, err := readPodStatus(u)
+	if err != nil {
+		return "", fmt.Errorf("read pod status: %w", err)
+	}
+
+	return status.healthStatus(), nil
 }
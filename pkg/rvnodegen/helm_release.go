@@ -0,0 +1,145 @@
+package rvnodegen
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	helmReleaseNameAnnotation      = "meta.helm.sh/release-name"
+	helmReleaseNamespaceAnnotation = "meta.helm.sh/release-namespace"
+)
+
+var gzipMagic = []byte{0x1f, 0x8b, 0x08}
+
+// HelmRelease is a Helm release stored in the cluster.
+type HelmRelease struct {
+	// Name is the name of the release.
+	Name string `json:"name"`
+	// Namespace is the namespace of the release.
+	Namespace string `json:"namespace"`
+	// Revision is the release revision.
+	Revision int `json:"revision"`
+	// Status is the release status.
+	Status string `json:"status"`
+	// Chart is the name of the chart.
+	Chart string `json:"chart"`
+	// ChartVersion is the version of the chart.
+	ChartVersion string `json:"chartVersion"`
+	// AppVersion is the version of the application in the chart.
+	AppVersion string `json:"appVersion,omitempty"`
+}
+
+// HealthStatus returns the health status for the release.
+func (r HelmRelease) HealthStatus() HealthStatusType {
+	switch r.Status {
+	case "failed":
+		return HealthStatusTypeFailure
+	case "pending-install", "pending-upgrade", "pending-rollback", "uninstalling", "unknown":
+		return HealthStatusTypeDegraded
+	default:
+		return HealthStatusTypeHealthy
+	}
+}
+
+// helmReleaseData is the subset of the Helm release record used by node gen.
+type helmReleaseData struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Version   int    `json:"version"`
+	Info      struct {
+		Status string `json:"status"`
+	} `json:"info"`
+	Chart struct {
+		Metadata struct {
+			Name       string `json:"name"`
+			Version    string `json:"version"`
+			AppVersion string `json:"appVersion"`
+		} `json:"metadata"`
+	} `json:"chart"`
+}
+
+// ListHelmReleases lists the latest revision of each Helm release in a namespace. Secrets that
+// can't be decoded are logged with logger and skipped.
+func ListHelmReleases(lister Lister, namespace string, logger logr.Logger) (map[string]HelmRelease, error) {
+	selector := labels.SelectorFromSet(labels.Set{"owner": "helm"})
+
+	secrets, err := lister.ByNamespace(namespace).List(secretGVK, selector)
+	if err != nil {
+		return nil, fmt.Errorf("list helm release secrets: %w", err)
+	}
+
+	releases := map[string]HelmRelease{}
+
+	for _, secret := range secrets {
+		release, err := decodeHelmRelease(secret)
+		if err != nil {
+			logger.Error(err, "Skip helm release secret",
+				"namespace", secret.GetNamespace(), "name", secret.GetName())
+			continue
+		}
+
+		if current, ok := releases[release.Name]; ok && current.Revision > release.Revision {
+			continue
+		}
+
+		releases[release.Name] = release
+	}
+
+	return releases, nil
+}
+
+func decodeHelmRelease(secret *unstructured.Unstructured) (HelmRelease, error) {
+	encoded, _, err := unstructured.NestedString(secret.Object, "data", "release")
+	if err != nil {
+		return HelmRelease{}, err
+	}
+
+	// secret data is base64 encoded, and helm base64 encodes the release again.
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return HelmRelease{}, fmt.Errorf("decode secret data: %w", err)
+	}
+
+	data, err = base64.StdEncoding.DecodeString(string(data))
+	if err != nil {
+		return HelmRelease{}, fmt.Errorf("decode release data: %w", err)
+	}
+
+	if bytes.HasPrefix(data, gzipMagic) {
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return HelmRelease{}, fmt.Errorf("read compressed release: %w", err)
+		}
+
+		data, err = ioutil.ReadAll(r)
+		if err != nil {
+			return HelmRelease{}, fmt.Errorf("decompress release: %w", err)
+		}
+	}
+
+	var rd helmReleaseData
+	if err := json.Unmarshal(data, &rd); err != nil {
+		return HelmRelease{}, fmt.Errorf("unmarshal release: %w", err)
+	}
+
+	release := HelmRelease{
+		Name:         rd.Name,
+		Namespace:    rd.Namespace,
+		Revision:     rd.Version,
+		Status:       rd.Info.Status,
+		Chart:        rd.Chart.Metadata.Name,
+		ChartVersion: rd.Chart.Metadata.Version,
+		AppVersion:   rd.Chart.Metadata.AppVersion,
+	}
+
+	return release, nil
+}
@@ -0,0 +1,115 @@
+package rvnodegen
+
+import (
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/utils/pointer"
+)
+
+// HelmReleaseGrouper groups objects by the Helm release that installed them.
+type HelmReleaseGrouper struct {
+	lister Lister
+	logger logr.Logger
+}
+
+var _ Grouper = &HelmReleaseGrouper{}
+
+// NewHelmReleaseGrouper creates an instance of HelmReleaseGrouper. Release secrets that can't be
+// decoded are logged with logger.
+func NewHelmReleaseGrouper(lister Lister, logger logr.Logger) *HelmReleaseGrouper {
+	h := &HelmReleaseGrouper{
+		lister: lister,
+		logger: logger,
+	}
+	return h
+}
+
+// Name is the name of the grouper.
+func (h *HelmReleaseGrouper) Name() string {
+	return "HelmRelease"
+}
+
+// Group places ungrouped nodes into Helm release groups.
+func (h *HelmReleaseGrouper) Group(nodes []GraphNode, objects map[string]*unstructured.Unstructured) ([]GraphNode, error) {
+	releasesByNamespace := map[string]map[string]HelmRelease{}
+	groups := map[string]*GraphNode{}
+	var groupIDs []string
+
+	for i := range nodes {
+		object, ok := groupableObject(nodes[i], objects)
+		if !ok {
+			continue
+		}
+
+		annotations := object.GetAnnotations()
+		name := annotations[helmReleaseNameAnnotation]
+		if name == "" {
+			continue
+		}
+
+		namespace := annotations[helmReleaseNamespaceAnnotation]
+		if namespace == "" {
+			namespace = object.GetNamespace()
+		}
+
+		id := helmReleaseGroupID(namespace, name)
+
+		group, ok := groups[id]
+		if !ok {
+			releases, ok := releasesByNamespace[namespace]
+			if !ok {
+				var err error
+				releases, err = ListHelmReleases(h.lister, namespace, h.logger)
+				if err != nil {
+					return nil, fmt.Errorf("list helm releases in namespace %q: %w", namespace, err)
+				}
+				releasesByNamespace[namespace] = releases
+			}
+
+			group = newHelmReleaseNode(id, name, namespace, releases)
+			groups[id] = group
+			groupIDs = append(groupIDs, id)
+		}
+
+		group.HealthStatus = worseHealthStatus(group.HealthStatus, nodes[i].HealthStatus)
+		nodes[i].Parent = pointer.StringPtr(id)
+	}
+
+	for _, id := range groupIDs {
+		nodes = append(nodes, *groups[id])
+	}
+
+	return nodes, nil
+}
+
+func newHelmReleaseNode(id, name, namespace string, releases map[string]HelmRelease) *GraphNode {
+	node := &GraphNode{
+		ID:       id,
+		Label:    name,
+		IsGroup:  pointer.StringPtr("yes"),
+		NodeType: NodeTypeHelmRelease,
+	}
+
+	release, ok := releases[name]
+	if !ok {
+		// the release record could have been removed while the objects remain.
+		release = HelmRelease{Name: name, Namespace: namespace, Status: "unknown"}
+	}
+
+	node.HealthStatus = release.HealthStatus()
+	node.Extra = map[string]interface{}{
+		"chart":        release.Chart,
+		"chartVersion": release.ChartVersion,
+		"appVersion":   release.AppVersion,
+		"revision":     release.Revision,
+		"status":       release.Status,
+	}
+
+	return node
+}
+
+func helmReleaseGroupID(namespace, name string) string {
+	return "helm-release:" + namespace + "/" + name
+}
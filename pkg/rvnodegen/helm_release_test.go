@@ -0,0 +1,67 @@
+package rvnodegen
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// recordingLogger records the messages of logged errors.
+type recordingLogger struct {
+	errors *[]string
+}
+
+var _ logr.Logger = recordingLogger{}
+
+func (l recordingLogger) Enabled() bool                                       { return true }
+func (l recordingLogger) Info(msg string, keysAndValues ...interface{})       {}
+func (l recordingLogger) V(level int) logr.Logger                             { return l }
+func (l recordingLogger) WithValues(keysAndValues ...interface{}) logr.Logger { return l }
+func (l recordingLogger) WithName(name string) logr.Logger                    { return l }
+
+func (l recordingLogger) Error(err error, msg string, keysAndValues ...interface{}) {
+	*l.errors = append(*l.errors, msg)
+}
+
+func newTestHelmReleaseSecret(namespace, name, release string) *unstructured.Unstructured {
+	secret := newTestObject(secretGVK, namespace, name)
+	secret.SetLabels(map[string]string{"owner": "helm"})
+	secret.Object["type"] = helmReleaseSecretType
+
+	encoded := base64.StdEncoding.EncodeToString([]byte(base64.StdEncoding.EncodeToString([]byte(release))))
+	secret.Object["data"] = map[string]interface{}{"release": encoded}
+
+	return secret
+}
+
+func TestListHelmReleases(t *testing.T) {
+	valid := newTestHelmReleaseSecret("default", "sh.helm.release.v1.podinfo.v2",
+		`{"name":"podinfo","namespace":"default","version":2,"info":{"status":"deployed"},`+
+			`"chart":{"metadata":{"name":"podinfo","version":"5.0.0","appVersion":"5.0.0"}}}`)
+	previous := newTestHelmReleaseSecret("default", "sh.helm.release.v1.podinfo.v1",
+		`{"name":"podinfo","namespace":"default","version":1,"info":{"status":"superseded"}}`)
+	invalid := newTestHelmReleaseSecret("default", "sh.helm.release.v1.broken.v1", "not json")
+
+	lister := newFakeClusterLister(t, nil, valid, previous, invalid)
+
+	var logged []string
+	releases, err := ListHelmReleases(lister, "default", recordingLogger{errors: &logged})
+	if err != nil {
+		t.Fatalf("list helm releases: %v", err)
+	}
+
+	if len(releases) != 1 {
+		t.Fatalf("got %d releases, want 1", len(releases))
+	}
+
+	release := releases["podinfo"]
+	if release.Revision != 2 || release.Status != "deployed" || release.ChartVersion != "5.0.0" {
+		t.Errorf("release = %+v, want revision 2 of chart 5.0.0", release)
+	}
+
+	if len(logged) != 1 {
+		t.Errorf("logged %v, want the undecodable secret", logged)
+	}
+}
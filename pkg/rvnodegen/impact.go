@@ -0,0 +1,147 @@
+package rvnodegen
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ImpactReport lists the objects that depend on an object, directly or transitively.
+type ImpactReport struct {
+	// Root is the object being changed.
+	Root ImpactedObject `json:"root"`
+	// HealthStatus is the worst health status of the dependents.
+	HealthStatus HealthStatusType `json:"healthStatus,omitempty"`
+	// Total is the number of dependents.
+	Total int `json:"total"`
+	// Relations are the dependents grouped by the relation they were reached through.
+	Relations map[EdgeRelation][]ImpactedObject `json:"relations"`
+}
+
+// ImpactedObject is an object in an impact report.
+type ImpactedObject struct {
+	ID           string           `json:"id"`
+	Kind         string           `json:"kind,omitempty"`
+	Namespace    string           `json:"namespace,omitempty"`
+	Name         string           `json:"name"`
+	HealthStatus HealthStatusType `json:"healthStatus,omitempty"`
+	// Relation is the relation to the object this one depends on.
+	Relation EdgeRelation `json:"relation,omitempty"`
+	// DependsOn is the id of the object this one depends on.
+	DependsOn string `json:"dependsOn,omitempty"`
+	// Depth is the number of relations between this object and the root.
+	Depth int `json:"depth,omitempty"`
+}
+
+// Impact builds the impact report for an object.
+func (n *NodeBuilder) Impact(root ObjectReference) (ImpactReport, error) {
+	nodes, ids, err := n.buildAround(root)
+	if err != nil {
+		return ImpactReport{}, err
+	}
+
+	return AnalyzeImpact(nodes, ids[0])
+}
+
+// AnalyzeImpact finds the dependents of a node. A node depends on the nodes it has edges to,
+// except for owner edges, where the owner depends on the objects it owns. Workloads depend on
+// the nodes they contain. Synthetic groups are not dependents.
+func AnalyzeImpact(nodes []GraphNode, rootID string) (ImpactReport, error) {
+	byID := nodesByID(nodes)
+
+	root, ok := byID[rootID]
+	if !ok {
+		return ImpactReport{}, fmt.Errorf("node %s: %w", rootID, ErrObjectNotFound)
+	}
+
+	dependents := impactAdjacency(nodes)
+
+	report := ImpactReport{
+		Root:      newImpactedObject(root),
+		Relations: map[EdgeRelation][]ImpactedObject{},
+	}
+
+	seen := map[string]bool{rootID: true}
+	frontier := []string{rootID}
+
+	for depth := 1; len(frontier) > 0; depth++ {
+		var next []string
+		for _, id := range frontier {
+			for _, dependent := range dependents[id] {
+				if seen[dependent.id] {
+					continue
+				}
+				seen[dependent.id] = true
+
+				node, ok := byID[dependent.id]
+				if !ok || node.Metadata == nil {
+					continue
+				}
+
+				object := newImpactedObject(node)
+				object.Relation = dependent.relation
+				object.DependsOn = id
+				object.Depth = depth
+
+				report.Relations[dependent.relation] = append(report.Relations[dependent.relation], object)
+				report.Total++
+				report.HealthStatus = worseHealthStatus(report.HealthStatus, node.HealthStatus)
+
+				next = append(next, dependent.id)
+			}
+		}
+		frontier = next
+	}
+
+	for relation := range report.Relations {
+		objects := report.Relations[relation]
+		sort.SliceStable(objects, func(i, j int) bool {
+			if objects[i].Depth != objects[j].Depth {
+				return objects[i].Depth < objects[j].Depth
+			}
+			return objects[i].ID < objects[j].ID
+		})
+	}
+
+	return report, nil
+}
+
+type impactDependent struct {
+	id       string
+	relation EdgeRelation
+}
+
+// impactAdjacency returns the dependents of each node.
+func impactAdjacency(nodes []GraphNode) map[string][]impactDependent {
+	dependents := map[string][]impactDependent{}
+
+	for _, node := range nodes {
+		for _, edge := range nodeEdges(node) {
+			if edge.Relation == EdgeRelationOwner {
+				dependents[edge.Source] = append(dependents[edge.Source], impactDependent{id: edge.Target, relation: edge.Relation})
+				continue
+			}
+			dependents[edge.Target] = append(dependents[edge.Target], impactDependent{id: edge.Source, relation: edge.Relation})
+		}
+
+		if node.Parent != nil {
+			dependents[node.ID] = append(dependents[node.ID], impactDependent{id: *node.Parent, relation: EdgeRelationOwner})
+		}
+	}
+
+	return dependents
+}
+
+func newImpactedObject(node GraphNode) ImpactedObject {
+	object := ImpactedObject{
+		ID:           node.ID,
+		Name:         node.Label,
+		HealthStatus: node.HealthStatus,
+	}
+
+	if node.Metadata != nil {
+		object.Kind = node.Metadata.Kind
+		object.Namespace = node.Metadata.Namespace
+	}
+
+	return object
+}
@@ -0,0 +1,41 @@
+package rvnodegen
+
+import (
+	"net/http"
+)
+
+// ImpactHandler is a HTTP handler for analyzing the impact of changing an object.
+type ImpactHandler struct {
+	lister  Lister
+	options []Option
+}
+
+var _ http.Handler = &ImpactHandler{}
+
+// NewImpactHandler creates an instance of ImpactHandler.
+func NewImpactHandler(lister Lister, options ...Option) *ImpactHandler {
+	h := &ImpactHandler{
+		lister:  lister,
+		options: options,
+	}
+	return h
+}
+
+// ServeHTTP serves the handler. The object is identified with the apiVersion, kind, namespace,
+// name and uid query parameters.
+func (h *ImpactHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	root := objectReferenceFromQuery(r.URL.Query(), "")
+	if err := root.Validate(); err != nil {
+		respondWithError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	nb := NewNodeBuilder(h.lister, h.options...)
+	report, err := nb.Impact(root)
+	if err != nil {
+		respondWithError(w, err, objectErrorStatus(err))
+		return
+	}
+
+	respondWithJSON(w, report, http.StatusOK)
+}
@@ -0,0 +1,67 @@
+package rvnodegen
+
+import (
+	"testing"
+)
+
+func TestNodeBuilder_Impact(t *testing.T) {
+	configMap := newTestObject(configMapGVK, "default", "config")
+	deployment, replicaSet, pod := newTestDeployment("default", "api", "config")
+
+	lister := newFakeClusterLister(t, fakeWorkloadResources, configMap, deployment, replicaSet, pod)
+
+	tests := []struct {
+		name     string
+		root     ObjectReference
+		wantRoot string
+	}{
+		{
+			name:     "config map",
+			root:     objectReference(configMap),
+			wantRoot: string(configMap.GetUID()),
+		},
+		{
+			name:     "summarized pod",
+			root:     objectReference(pod),
+			wantRoot: string(replicaSet.GetUID()),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			report, err := NewNodeBuilder(lister).Impact(test.root)
+			if err != nil {
+				t.Fatalf("impact: %v", err)
+			}
+
+			if report.Root.ID != test.wantRoot {
+				t.Errorf("root = %q, want %q", report.Root.ID, test.wantRoot)
+			}
+		})
+	}
+}
+
+func TestNodeBuilder_Impact_dependents(t *testing.T) {
+	configMap := newTestObject(configMapGVK, "default", "config")
+	deployment, replicaSet, pod := newTestDeployment("default", "api", "config")
+
+	lister := newFakeClusterLister(t, fakeWorkloadResources, configMap, deployment, replicaSet, pod)
+
+	report, err := NewNodeBuilder(lister).Impact(objectReference(configMap))
+	if err != nil {
+		t.Fatalf("impact: %v", err)
+	}
+
+	dependents := map[string]bool{}
+	for _, objects := range report.Relations {
+		for _, object := range objects {
+			dependents[object.ID] = true
+		}
+	}
+
+	for _, object := range []string{string(replicaSet.GetUID()), string(deployment.GetUID())} {
+		if !dependents[object] {
+			t.Errorf("%s is not a dependent, dependents = %v", object, dependents)
+		}
+	}
+}
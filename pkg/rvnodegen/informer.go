@@ -1,55 +1,260 @@
 package rvnodegen
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
 )
 
 var (
+	// ErrUnknownResource is returned when a group/version/kind is not served by the cluster, or
+	// the user can't read it.
+	ErrUnknownResource = errors.New("unknown resource")
+
 	// BannedResources are resources that will not be used in node generation.
 	BannedResources = []schema.GroupVersionResource{
 		{Group: "extensions", Version: "v1beta1", Resource: "ingresses"},
 	}
 )
 
-// InformerManager in a manager for multiple informers.
+// InformerManager in a manager for multiple informers. Resources the user can list and watch
+// across namespaces have cluster wide informers. Namespaced resources the user can only read in
+// some namespaces have informers in those namespaces, and other resources are skipped. Resources
+// whose caches don't sync in time are skipped as well, and their informers are stopped.
 type InformerManager struct {
-	factory dynamicinformer.DynamicSharedInformerFactory
-	mapping map[schema.GroupVersionKind]schema.GroupVersionResource
+	factory            dynamicinformer.DynamicSharedInformerFactory
+	namespaceFactories map[string]dynamicinformer.DynamicSharedInformerFactory
+	mapping            map[schema.GroupVersionKind]schema.GroupVersionResource
+	namespaced         map[schema.GroupVersionKind]bool
+	access             map[schema.GroupVersionKind]resourceAccess
+	skipped            []SkippedResource
+	logger             logr.Logger
+
+	mu    sync.Mutex
+	stops map[informerKey]chan struct{}
+}
+
+// informerKey identifies an informer by resource and namespace. Cluster wide informers have an
+// empty namespace.
+type informerKey struct {
+	resource  schema.GroupVersionResource
+	namespace string
 }
 
-// NewInformerManager creates an instance of InformerManager.
-func NewInformerManager(client *Client) (*InformerManager, error) {
+// NewInformerManager creates an instance of InformerManager. The user's access is reviewed
+// before informers are started, so informers are only started for resources the user can read.
+// When the user can't list namespaces, the namespaces set with AccessNamespaces are checked for
+// namespaced access. Informers have the time set with CacheSyncTimeout to sync.
+func NewInformerManager(client *Client, options ...Option) (*InformerManager, error) {
+	opts := buildOptionConfig(options...)
+	ctx := context.Background()
 	defaultResync := 180 * time.Second
 
-	informerFactory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(
-		client.DynamicClient(),
-		defaultResync,
-		"", // this doesn't seem to matter
-		nil)
-	mapping, err := primeFactory(client.DiscoveryClient(), informerFactory)
+	resources, err := discoverResources(client.DiscoveryClient())
 	if err != nil {
-		return nil, fmt.Errorf("prime informer factory: %w", err)
+		return nil, fmt.Errorf("discover resources: %w", err)
 	}
 
-	stopCh := make(chan struct{}, 1)
-	informerFactory.Start(stopCh)
+	reviewer := newAccessReviewer(client.AuthorizationClient())
+
+	namespaces, err := accessNamespaces(ctx, client, reviewer, opts.accessNamespaces)
+	if err != nil {
+		return nil, err
+	}
+
+	access, skipped, err := reviewer.review(ctx, resources, namespaces)
+	if err != nil {
+		return nil, fmt.Errorf("review access: %w", err)
+	}
 
 	i := &InformerManager{
-		factory: informerFactory,
-		mapping: mapping,
+		factory: dynamicinformer.NewFilteredDynamicSharedInformerFactory(
+			client.DynamicClient(),
+			defaultResync,
+			"", // this doesn't seem to matter
+			nil),
+		namespaceFactories: map[string]dynamicinformer.DynamicSharedInformerFactory{},
+		mapping:            map[schema.GroupVersionKind]schema.GroupVersionResource{},
+		namespaced:         map[schema.GroupVersionKind]bool{},
+		access:             access,
+		skipped:            skipped,
+		logger:             opts.logger,
+		stops:              map[informerKey]chan struct{}{},
+	}
+
+	for _, resource := range resources {
+		i.namespaced[resource.gvk] = resource.namespaced
+
+		ra, ok := access[resource.gvk]
+		if !ok {
+			continue
+		}
+
+		i.mapping[resource.gvk] = resource.gvr
+
+		if ra.clusterWide {
+			i.factory.ForResource(resource.gvr)
+			continue
+		}
+
+		for _, namespace := range ra.namespaces {
+			factory, ok := i.namespaceFactories[namespace]
+			if !ok {
+				factory = dynamicinformer.NewFilteredDynamicSharedInformerFactory(
+					client.DynamicClient(), defaultResync, namespace, nil)
+				i.namespaceFactories[namespace] = factory
+			}
+			factory.ForResource(resource.gvr)
+		}
 	}
 
-	informerFactory.Start(stopCh)
-	informerFactory.WaitForCacheSync(stopCh)
+	// informers are run individually rather than by their factories, so informers for resources
+	// that are skipped can be stopped.
+	informersByKey := map[informerKey]cache.SharedIndexInformer{}
+	for gvk, resource := range i.mapping {
+		ra := access[gvk]
+		if ra.clusterWide {
+			informersByKey[informerKey{resource: resource}] = i.factory.ForResource(resource).Informer()
+			continue
+		}
+
+		for _, namespace := range ra.namespaces {
+			key := informerKey{resource: resource, namespace: namespace}
+			informersByKey[key] = i.namespaceFactories[namespace].ForResource(resource).Informer()
+		}
+	}
+
+	for key, informer := range informersByKey {
+		stopCh := make(chan struct{})
+		i.stops[key] = stopCh
+		go informer.Run(stopCh)
+	}
+
+	syncCtx, cancel := context.WithTimeout(ctx, opts.cacheSyncTimeout)
+	defer cancel()
+
+	synced := map[schema.GroupVersionResource]bool{}
+	namespaceSynced := map[string]map[schema.GroupVersionResource]bool{}
+	for key, informer := range informersByKey {
+		ok := cache.WaitForCacheSync(syncCtx.Done(), informer.HasSynced)
+		if key.namespace == "" {
+			synced[key.resource] = ok
+			continue
+		}
+
+		if namespaceSynced[key.namespace] == nil {
+			namespaceSynced[key.namespace] = map[schema.GroupVersionResource]bool{}
+		}
+		namespaceSynced[key.namespace][key.resource] = ok
+	}
+
+	i.skipUnsynced(synced, namespaceSynced, opts.cacheSyncTimeout)
 
 	return i, nil
 }
 
+// skipUnsynced skips resources whose caches did not sync. Namespaced access is limited to the
+// namespaces that synced.
+func (im *InformerManager) skipUnsynced(synced map[schema.GroupVersionResource]bool, namespaceSynced map[string]map[schema.GroupVersionResource]bool, timeout time.Duration) {
+	reason := fmt.Sprintf("cache did not sync within %s", timeout)
+
+	for gvk, resource := range im.mapping {
+		ra := im.access[gvk]
+
+		if ra.clusterWide {
+			if synced[resource] {
+				continue
+			}
+
+			im.stop(informerKey{resource: resource})
+			delete(im.mapping, gvk)
+			delete(im.access, gvk)
+			im.skipped = append(im.skipped, SkippedResource{
+				Group:    gvk.Group,
+				Version:  gvk.Version,
+				Kind:     gvk.Kind,
+				Resource: resource.Resource,
+				Reason:   reason,
+			})
+			continue
+		}
+
+		var readable, unsynced []string
+		for _, namespace := range ra.namespaces {
+			if namespaceSynced[namespace][resource] {
+				readable = append(readable, namespace)
+			} else {
+				im.stop(informerKey{resource: resource, namespace: namespace})
+				unsynced = append(unsynced, namespace)
+			}
+		}
+
+		if len(unsynced) == 0 {
+			continue
+		}
+
+		// namespaced access is already reported as skipped, so the report is updated.
+		for j := range im.skipped {
+			sr := &im.skipped[j]
+			if sr.Group != gvk.Group || sr.Version != gvk.Version || sr.Kind != gvk.Kind {
+				continue
+			}
+
+			sr.Namespaces = readable
+			sr.Reason = "cannot list and watch across namespaces"
+			if len(readable) > 0 {
+				sr.Reason += ", reading namespaces " + strings.Join(readable, ", ")
+			}
+			sr.Reason += fmt.Sprintf(", %s in namespaces %s", reason, strings.Join(unsynced, ", "))
+		}
+
+		if len(readable) == 0 {
+			delete(im.mapping, gvk)
+			delete(im.access, gvk)
+			continue
+		}
+
+		ra.namespaces = readable
+		im.access[gvk] = ra
+	}
+
+	sortSkippedResources(im.skipped)
+}
+
+// Stop stops the manager's informers. Listers created by the manager stop receiving updates. It
+// is safe to call more than once.
+func (im *InformerManager) Stop() {
+	im.mu.Lock()
+	defer im.mu.Unlock()
+
+	for key, stopCh := range im.stops {
+		close(stopCh)
+		delete(im.stops, key)
+	}
+}
+
+// stop stops the informer for a resource in a namespace.
+func (im *InformerManager) stop(key informerKey) {
+	im.mu.Lock()
+	defer im.mu.Unlock()
+
+	if stopCh, ok := im.stops[key]; ok {
+		close(stopCh)
+		delete(im.stops, key)
+	}
+}
+
 // Lister returns a lister given a resource.
 func (im *InformerManager) Lister() Lister {
 	return newLister(im)
@@ -59,16 +264,70 @@ func (im *InformerManager) Lister() Lister {
 func (im *InformerManager) Resource(gvk schema.GroupVersionKind) (schema.GroupVersionResource, error) {
 	resource, ok := im.mapping[gvk]
 	if !ok {
-		return schema.GroupVersionResource{}, fmt.Errorf("not found")
+		return schema.GroupVersionResource{}, fmt.Errorf("%s: %w", gvk, ErrUnknownResource)
 	}
 
 	return resource, nil
 }
 
-func primeFactory(
-	discoveryClient discovery.DiscoveryInterface,
-	factory dynamicinformer.DynamicSharedInformerFactory) (map[schema.GroupVersionKind]schema.GroupVersionResource, error) {
-	mapping := map[schema.GroupVersionKind]schema.GroupVersionResource{}
+// Kinds returns the group/version/kinds the user can read.
+func (im *InformerManager) Kinds() []schema.GroupVersionKind {
+	kinds := map[schema.GroupVersionKind]bool{}
+	for gvk := range im.mapping {
+		kinds[gvk] = true
+	}
+	return sortedKinds(kinds)
+}
+
+// IsNamespaced returns true if a group/version/kind is namespaced. The scope of resources the
+// user can't read is known as well.
+func (im *InformerManager) IsNamespaced(gvk schema.GroupVersionKind) (bool, error) {
+	namespaced, ok := im.namespaced[gvk]
+	if !ok {
+		return false, fmt.Errorf("%s: %w", gvk, ErrUnknownResource)
+	}
+
+	return namespaced, nil
+}
+
+// SkippedResources returns the resources that are not read across namespaces because the user
+// can't list and watch them or their caches did not sync.
+func (im *InformerManager) SkippedResources() []SkippedResource {
+	return im.skipped
+}
+
+// informers returns the informers for a resource in a namespace. An empty namespace returns the
+// informers for every namespace the resource is read in. Resources that can't be read in the
+// namespace are unknown.
+func (im *InformerManager) informers(gvk schema.GroupVersionKind, namespace string) ([]informers.GenericInformer, error) {
+	resource, err := im.Resource(gvk)
+	if err != nil {
+		return nil, err
+	}
+
+	access := im.access[gvk]
+	if access.clusterWide {
+		return []informers.GenericInformer{im.factory.ForResource(resource)}, nil
+	}
+
+	var out []informers.GenericInformer
+	for _, readable := range access.namespaces {
+		if namespace == "" || namespace == readable {
+			out = append(out, im.namespaceFactories[readable].ForResource(resource))
+		}
+	}
+
+	if len(out) == 0 {
+		return nil, fmt.Errorf("%s in namespace %s: %w", gvk, namespace, ErrUnknownResource)
+	}
+
+	return out, nil
+}
+
+// discoverResources discovers the preferred version of the resources that can be listed and
+// watched.
+func discoverResources(discoveryClient discovery.DiscoveryInterface) ([]discoveredResource, error) {
+	var resources []discoveredResource
 
 	resourceLists, err := discoveryClient.ServerPreferredResources()
 	if err != nil {
@@ -96,19 +355,48 @@ func primeFactory(
 				continue
 			}
 
-			factory.ForResource(resource)
-
 			gvk := schema.GroupVersionKind{
 				Group:   resource.Group,
 				Version: resource.Version,
 				Kind:    apiResource.Kind,
 			}
 
-			mapping[gvk] = resource
+			resources = append(resources, discoveredResource{
+				gvk:        gvk,
+				gvr:        resource,
+				namespaced: apiResource.Namespaced,
+			})
 		}
 	}
 
-	return mapping, nil
+	return resources, nil
+}
+
+// accessNamespaces returns the namespaces to check for namespaced access. They are the cluster's
+// namespaces if the user can list them, otherwise they are the fallback namespaces.
+func accessNamespaces(ctx context.Context, client *Client, reviewer *accessReviewer, fallback []string) ([]string, error) {
+	namespaceResource := schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}
+
+	ok, err := reviewer.canRead(ctx, namespaceResource.GroupResource(), "")
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		return fallback, nil
+	}
+
+	list, err := client.DynamicClient().Resource(namespaceResource).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("list namespaces: %w", err)
+	}
+
+	var namespaces []string
+	for _, item := range list.Items {
+		namespaces = append(namespaces, item.GetName())
+	}
+
+	return namespaces, nil
 }
 
 func stringsIncludes(s string, sl []string) bool {
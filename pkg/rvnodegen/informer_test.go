@@ -0,0 +1,32 @@
+package rvnodegen
+
+import (
+	"testing"
+)
+
+func TestInformerManager_Stop(t *testing.T) {
+	im := newFakeInformerManager(t, nil, newTestObject(configMapGVK, "default", "config"))
+
+	im.mu.Lock()
+	stops := make([]chan struct{}, 0, len(im.stops))
+	for _, stopCh := range im.stops {
+		stops = append(stops, stopCh)
+	}
+	im.mu.Unlock()
+
+	if len(stops) != len(fakeCoreResources) {
+		t.Fatalf("running informers = %d, want %d", len(stops), len(fakeCoreResources))
+	}
+
+	im.Stop()
+	// stopping again does not close the stop channels twice.
+	im.Stop()
+
+	for _, stopCh := range stops {
+		select {
+		case <-stopCh:
+		default:
+			t.Fatal("informer was not stopped")
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package rvnodegen
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// IngressResourceVisitor visits an ingress resource.
+type IngressResourceVisitor struct {
+	lister Lister
+}
+
+var _ ResourceVisitor = &IngressResourceVisitor{}
+
+// NewIngressResourceVisitor creates an instance of IngressResourceVisitor.
+func NewIngressResourceVisitor(lister Lister) *IngressResourceVisitor {
+	i := &IngressResourceVisitor{
+		lister: lister,
+	}
+	return i
+}
+
+// Name is the name of the resource visitor.
+func (i *IngressResourceVisitor) Name() string {
+	return "Ingress"
+}
+
+// Matches returns a group/version/kind that this resource visitor matches.
+func (i *IngressResourceVisitor) Matches(gvk schema.GroupVersionKind) bool {
+	return ingressGVK.String() == gvk.String()
+}
+
+// Visit visits an ingress resource. It targets backend services, TLS secrets and the ingress
+// class.
+func (i *IngressResourceVisitor) Visit(object *unstructured.Unstructured, node GraphNode, visitor *Visitor) (GraphNode, error) {
+	references, err := ingressReferences(object)
+	if err != nil {
+		return GraphNode{}, err
+	}
+
+	return visitEdgeReferences(visitor, i.Name(), object, references, node)
+}
+
+// ingressReferences returns the references an ingress makes to its backend services, TLS
+// secrets and ingress class.
+func ingressReferences(object *unstructured.Unstructured) ([]edgeReference, error) {
+	backends, err := ingressBackends(object)
+	if err != nil {
+		return nil, fmt.Errorf("find ingress backends: %w", err)
+	}
+
+	var references []edgeReference
+	for _, backend := range backends {
+		references = append(references, edgeReference{gvk: serviceGVK, namespace: object.GetNamespace(),
+			name: backend.service, relation: EdgeRelationIngressBackend, attributes: backend.attributes,
+			field: backend.field, verb: "routes to", detail: backend.detail()})
+	}
+
+	tls, _, err := unstructured.NestedSlice(object.Object, "spec", "tls")
+	if err != nil {
+		return nil, err
+	}
+
+	for j := range tls {
+		entry, ok := tls[j].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		secretName, _, _ := unstructured.NestedString(entry, "secretName")
+		if secretName == "" {
+			continue
+		}
+
+		references = append(references, edgeReference{gvk: secretGVK, namespace: object.GetNamespace(),
+			name: secretName, relation: EdgeRelationCustom, attributes: map[string]string{"field": "tls"},
+			field: "spec.tls", verb: "terminates TLS with"})
+	}
+
+	className, _, err := unstructured.NestedString(object.Object, "spec", "ingressClassName")
+	if err != nil {
+		return nil, err
+	}
+
+	if className != "" {
+		references = append(references, edgeReference{gvk: ingressClassGVK, name: className,
+			relation: EdgeRelationCustom, attributes: map[string]string{"field": "ingressClassName"},
+			field: "spec.ingressClassName", verb: "uses"})
+	}
+
+	return references, nil
+}
+
+// ingressBackend is a service an ingress routes to.
+type ingressBackend struct {
+	service    string
+	field      string
+	attributes map[string]string
+}
+
+// detail describes the host, path and port routed to the backend.
+func (b ingressBackend) detail() string {
+	var parts []string
+	for _, key := range []string{"host", "path", "port"} {
+		if value, ok := b.attributes[key]; ok {
+			parts = append(parts, key+" "+value)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+func ingressBackends(object *unstructured.Unstructured) ([]ingressBackend, error) {
+	var backends []ingressBackend
+
+	defaultBackend, found, err := unstructured.NestedMap(object.Object, "spec", "defaultBackend")
+	if err != nil {
+		return nil, err
+	}
+
+	if found {
+		if backend, ok := newIngressBackend(defaultBackend, "spec.defaultBackend", "", ""); ok {
+			backends = append(backends, backend)
+		}
+	}
+
+	rules, _, err := unstructured.NestedSlice(object.Object, "spec", "rules")
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range rules {
+		rule, ok := rules[i].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		host, _, _ := unstructured.NestedString(rule, "host")
+		paths, _, _ := unstructured.NestedSlice(rule, "http", "paths")
+
+		for j := range paths {
+			path, ok := paths[j].(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			pathValue, _, _ := unstructured.NestedString(path, "path")
+			backendMap, _, _ := unstructured.NestedMap(path, "backend")
+
+			if backend, ok := newIngressBackend(backendMap, "spec.rules", host, pathValue); ok {
+				backends = append(backends, backend)
+			}
+		}
+	}
+
+	return backends, nil
+}
+
+func newIngressBackend(backend map[string]interface{}, field, host, path string) (ingressBackend, bool) {
+	service, _, _ := unstructured.NestedString(backend, "service", "name")
+	if service == "" {
+		return ingressBackend{}, false
+	}
+
+	attributes := map[string]string{}
+	if host != "" {
+		attributes["host"] = host
+	}
+	if path != "" {
+		attributes["path"] = path
+	}
+
+	if number, found, _ := unstructured.NestedInt64(backend, "service", "port", "number"); found {
+		attributes["port"] = strconv.FormatInt(number, 10)
+	} else if name, _, _ := unstructured.NestedString(backend, "service", "port", "name"); name != "" {
+		attributes["port"] = name
+	}
+
+	return ingressBackend{service: service, field: field, attributes: attributes}, true
+}
@@ -0,0 +1,87 @@
+package rvnodegen
+
+import (
+	"fmt"
+
+	restclient "k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// KubeConfig selects the kube config used to connect to clusters, and the context, cluster and
+// user to use from it. Kube configs are loaded with the client-go loading rules: an explicit
+// path, otherwise the files in KUBECONFIG merged, otherwise ~/.kube/config. When no kube config
+// is found and node gen is running in a pod, the pod's service account is used.
+type KubeConfig struct {
+	// Path is the kube config file. If it is empty, the loading rules are used.
+	Path string
+	// Context overrides the current context.
+	Context string
+	// Cluster overrides the context's cluster.
+	Cluster string
+	// User overrides the context's user.
+	User string
+	// Namespace overrides the context's namespace.
+	Namespace string
+}
+
+// RESTConfig returns the REST config for the selected context.
+func (kc KubeConfig) RESTConfig() (*restclient.Config, error) {
+	return kc.clientConfig().ClientConfig()
+}
+
+// DefaultNamespace returns the namespace for the selected context. It is the pod's namespace
+// when running in a pod with an in-cluster config, and DefaultNamespace if the context does
+// not have a namespace or there is no kube config.
+func (kc KubeConfig) DefaultNamespace() (string, error) {
+	namespace, _, err := kc.clientConfig().Namespace()
+	if err != nil {
+		if !clientcmd.IsEmptyConfig(err) {
+			return "", fmt.Errorf("kube config namespace: %w", err)
+		}
+		namespace = kc.Namespace
+	}
+
+	if namespace == "" {
+		namespace = DefaultNamespace
+	}
+
+	return namespace, nil
+}
+
+// forContext returns the kube config with a different context. The context is unchanged if it is
+// empty.
+func (kc KubeConfig) forContext(context string) KubeConfig {
+	if context != "" {
+		kc.Context = context
+	}
+	return kc
+}
+
+// load loads the merged kube config without overrides.
+func (kc KubeConfig) load() (*clientcmdapi.Config, error) {
+	config, err := kc.loadingRules().Load()
+	if err != nil {
+		return nil, fmt.Errorf("load kube config: %w", err)
+	}
+	return config, nil
+}
+
+func (kc KubeConfig) loadingRules() *clientcmd.ClientConfigLoadingRules {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	rules.ExplicitPath = kc.Path
+	return rules
+}
+
+func (kc KubeConfig) clientConfig() clientcmd.ClientConfig {
+	overrides := &clientcmd.ConfigOverrides{
+		CurrentContext: kc.Context,
+		Context: clientcmdapi.Context{
+			Cluster:   kc.Cluster,
+			AuthInfo:  kc.User,
+			Namespace: kc.Namespace,
+		},
+	}
+
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(kc.loadingRules(), overrides)
+}
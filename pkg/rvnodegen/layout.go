@@ -0,0 +1,406 @@
+package rvnodegen
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+)
+
+// LayoutAlgorithm is a server side graph layout algorithm.
+type LayoutAlgorithm string
+
+const (
+	// LayoutNone leaves layout to clients.
+	LayoutNone LayoutAlgorithm = ""
+	// LayoutLayered is a layered (Sugiyama style) layout with edges flowing left to right.
+	LayoutLayered LayoutAlgorithm = "layered"
+)
+
+func parseLayoutAlgorithm(s string) (LayoutAlgorithm, error) {
+	switch LayoutAlgorithm(s) {
+	case LayoutNone, LayoutLayered:
+		return LayoutAlgorithm(s), nil
+	default:
+		return "", fmt.Errorf("unknown layout %q", s)
+	}
+}
+
+const (
+	layoutNodeHeight     = 40
+	layoutMinNodeWidth   = 120
+	layoutCharWidth      = 7
+	layoutLabelPadding   = 24
+	layoutLayerGap       = 80
+	layoutNodeGap        = 24
+	layoutGroupPadding   = 16
+	layoutGroupHeader    = 24
+	layoutOrderingSweeps = 4
+)
+
+// NodeLayout is the position and size of a node. For groups, it is the bounding box of the
+// group's members.
+type NodeLayout struct {
+	// X is the left edge of the node.
+	X float64 `json:"x"`
+	// Y is the top edge of the node.
+	Y float64 `json:"y"`
+	// Width is the width of the node.
+	Width float64 `json:"width"`
+	// Height is the height of the node.
+	Height float64 `json:"height"`
+}
+
+// LayeredLayout assigns positions to nodes with a layered layout. Each group's members are laid
+// out within the group. The order of nodes within a layer is seeded from the previous layout,
+// so positions stay stable as a graph is rebuilt.
+type LayeredLayout struct {
+	mu       sync.Mutex
+	previous map[string]NodeLayout
+}
+
+// NewLayeredLayout creates an instance of LayeredLayout.
+func NewLayeredLayout() *LayeredLayout {
+	l := &LayeredLayout{
+		previous: map[string]NodeLayout{},
+	}
+	return l
+}
+
+// Layout returns a copy of nodes with layouts.
+func (l *LayeredLayout) Layout(nodes []GraphNode) []GraphNode {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	tree := newGraphTree(nodes)
+
+	parents := map[string]string{}
+	for id, children := range tree.children {
+		for _, child := range children {
+			parents[child.ID] = id
+		}
+	}
+
+	ll := &layeredLevelLayout{
+		tree:     tree,
+		parents:  parents,
+		edges:    graphEdges(nodes),
+		previous: l.previous,
+	}
+
+	layouts, _, _ := ll.layoutMembers(tree.roots)
+
+	out := make([]GraphNode, len(nodes))
+	for i := range nodes {
+		node := nodes[i]
+		if layout, ok := layouts[node.ID]; ok {
+			node.Layout = &layout
+		}
+		out[i] = node
+	}
+
+	l.previous = layouts
+
+	return out
+}
+
+// layeredLevelLayout lays out one level of the group hierarchy at a time.
+type layeredLevelLayout struct {
+	tree     graphTree
+	parents  map[string]string
+	edges    []Edge
+	previous map[string]NodeLayout
+}
+
+// layoutMembers lays out members and their descendants relative to the members' origin. It
+// returns the layouts and the size of the members' bounding box.
+func (ll *layeredLevelLayout) layoutMembers(members []GraphNode) (map[string]NodeLayout, float64, float64) {
+	layouts := map[string]NodeLayout{}
+	sizes := map[string]NodeLayout{}
+	memberIDs := map[string]bool{}
+
+	for _, member := range members {
+		memberIDs[member.ID] = true
+
+		size := NodeLayout{
+			Width:  math.Max(layoutMinNodeWidth, float64(len(member.Label)*layoutCharWidth+layoutLabelPadding)),
+			Height: layoutNodeHeight,
+		}
+
+		if children := ll.tree.children[member.ID]; len(children) > 0 {
+			childLayouts, width, height := ll.layoutMembers(children)
+			for id, layout := range childLayouts {
+				layout.X += layoutGroupPadding
+				layout.Y += layoutGroupHeader + layoutGroupPadding
+				layouts[id] = layout
+			}
+
+			size.Width = math.Max(size.Width, width+2*layoutGroupPadding)
+			size.Height = height + layoutGroupHeader + 2*layoutGroupPadding
+		}
+
+		sizes[member.ID] = size
+	}
+
+	layers := ll.orderLayers(members, ll.levelEdges(memberIDs))
+
+	var x, height float64
+	for _, layer := range layers {
+		var y, width float64
+		for _, id := range layer {
+			size := sizes[id]
+			size.X = x
+			size.Y = y
+			sizes[id] = size
+
+			y += size.Height + layoutNodeGap
+			width = math.Max(width, size.Width)
+		}
+
+		x += width + layoutLayerGap
+		height = math.Max(height, y-layoutNodeGap)
+	}
+
+	width := math.Max(0, x-layoutLayerGap)
+
+	// members are positioned, so their descendants can be moved into place.
+	for id, layout := range layouts {
+		member := ll.memberOf(id, memberIDs)
+		layout.X += sizes[member].X
+		layout.Y += sizes[member].Y
+		layouts[id] = layout
+	}
+
+	for id, size := range sizes {
+		layouts[id] = size
+	}
+
+	return layouts, width, height
+}
+
+// levelEdges lifts edges between descendants of members to edges between the members.
+func (ll *layeredLevelLayout) levelEdges(memberIDs map[string]bool) [][2]string {
+	seen := map[[2]string]bool{}
+	var edges [][2]string
+
+	for _, edge := range ll.edges {
+		source := ll.memberOf(edge.Source, memberIDs)
+		target := ll.memberOf(edge.Target, memberIDs)
+		if source == "" || target == "" || source == target {
+			continue
+		}
+
+		key := [2]string{source, target}
+		if !seen[key] {
+			seen[key] = true
+			edges = append(edges, key)
+		}
+	}
+
+	return edges
+}
+
+// memberOf returns the member containing a node, or an empty string if no member contains it.
+func (ll *layeredLevelLayout) memberOf(id string, memberIDs map[string]bool) string {
+	for {
+		if memberIDs[id] {
+			return id
+		}
+
+		parent, ok := ll.parents[id]
+		if !ok {
+			return ""
+		}
+		id = parent
+	}
+}
+
+// orderLayers assigns members to layers by their longest path from a source, then orders each
+// layer with barycenter sweeps to reduce crossings. Cycles are broken by reversing back edges.
+// Layers are seeded with their order in the previous layout, which also breaks barycenter ties.
+// Layers whose members were all in one layer of the previous layout keep their previous order,
+// so nodes don't move when unrelated parts of the graph change.
+func (ll *layeredLevelLayout) orderLayers(members []GraphNode, edges [][2]string) [][]string {
+	ids := make([]string, len(members))
+	for i := range members {
+		ids[i] = members[i].ID
+	}
+	sort.Strings(ids)
+
+	dag := acyclicEdges(ids, edges)
+
+	predecessors := map[string][]string{}
+	successors := map[string][]string{}
+	inDegree := map[string]int{}
+	for _, edge := range dag {
+		predecessors[edge[1]] = append(predecessors[edge[1]], edge[0])
+		successors[edge[0]] = append(successors[edge[0]], edge[1])
+		inDegree[edge[1]]++
+	}
+
+	layerOf := map[string]int{}
+	var queue []string
+	for _, id := range ids {
+		if inDegree[id] == 0 {
+			queue = append(queue, id)
+		}
+	}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, next := range successors[id] {
+			if layerOf[id]+1 > layerOf[next] {
+				layerOf[next] = layerOf[id] + 1
+			}
+			inDegree[next]--
+			if inDegree[next] == 0 {
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	var layers [][]string
+	for _, id := range ids {
+		layer := layerOf[id]
+		for len(layers) <= layer {
+			layers = append(layers, nil)
+		}
+		layers[layer] = append(layers[layer], id)
+	}
+
+	// seed the order from the previous layout. New nodes go after existing ones.
+	for _, layer := range layers {
+		layer := layer
+		sort.SliceStable(layer, func(i, j int) bool {
+			a, aOK := ll.previous[layer[i]]
+			b, bOK := ll.previous[layer[j]]
+			if aOK && bOK {
+				return a.Y < b.Y
+			}
+			return aOK && !bOK
+		})
+	}
+
+	rank := map[string]int{}
+	unchanged := make([]bool, len(layers))
+	for i, layer := range layers {
+		for j, id := range layer {
+			rank[id] = j
+		}
+		unchanged[i] = ll.isPreviousLayer(layer)
+	}
+
+	for sweep := 0; sweep < layoutOrderingSweeps; sweep++ {
+		for i := 1; i < len(layers); i++ {
+			if !unchanged[i] {
+				orderByBarycenter(layers[i], layers[i-1], predecessors, rank)
+			}
+		}
+		for i := len(layers) - 2; i >= 0; i-- {
+			if !unchanged[i] {
+				orderByBarycenter(layers[i], layers[i+1], successors, rank)
+			}
+		}
+	}
+
+	return layers
+}
+
+// isPreviousLayer returns true if the members of a layer were all in one layer of the previous
+// layout. Members of a layer share an x position.
+func (ll *layeredLevelLayout) isPreviousLayer(layer []string) bool {
+	if len(layer) == 0 {
+		return false
+	}
+
+	first, ok := ll.previous[layer[0]]
+	if !ok {
+		return false
+	}
+
+	for _, id := range layer[1:] {
+		layout, ok := ll.previous[id]
+		if !ok || layout.X != first.X {
+			return false
+		}
+	}
+
+	return true
+}
+
+// acyclicEdges returns edges with the back edges found by a depth first search reversed.
+func acyclicEdges(ids []string, edges [][2]string) [][2]string {
+	successors := map[string][]string{}
+	for _, edge := range edges {
+		successors[edge[0]] = append(successors[edge[0]], edge[1])
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	state := map[string]int{}
+	var out [][2]string
+
+	var visit func(id string)
+	visit = func(id string) {
+		state[id] = visiting
+		for _, next := range successors[id] {
+			if state[next] == visiting {
+				out = append(out, [2]string{next, id})
+				continue
+			}
+
+			out = append(out, [2]string{id, next})
+			if state[next] == unvisited {
+				visit(next)
+			}
+		}
+		state[id] = visited
+	}
+
+	for _, id := range ids {
+		if state[id] == unvisited {
+			visit(id)
+		}
+	}
+
+	return out
+}
+
+// orderByBarycenter orders a layer by the mean position of each node's neighbors in an adjacent
+// layer. Nodes without neighbors keep their position, and ties are ordered by rank.
+func orderByBarycenter(layer, adjacent []string, neighbors map[string][]string, rank map[string]int) {
+	position := map[string]int{}
+	for i, id := range adjacent {
+		position[id] = i
+	}
+
+	barycenter := map[string]float64{}
+	for i, id := range layer {
+		var sum float64
+		var count int
+		for _, neighbor := range neighbors[id] {
+			if p, ok := position[neighbor]; ok {
+				sum += float64(p)
+				count++
+			}
+		}
+
+		if count == 0 {
+			barycenter[id] = float64(i)
+			continue
+		}
+		barycenter[id] = sum / float64(count)
+	}
+
+	sort.SliceStable(layer, func(i, j int) bool {
+		a, b := barycenter[layer[i]], barycenter[layer[j]]
+		if a != b {
+			return a < b
+		}
+		return rank[layer[i]] < rank[layer[j]]
+	})
+}
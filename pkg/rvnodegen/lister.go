@@ -2,6 +2,7 @@ package rvnodegen
 
 import (
 	"fmt"
+	"sort"
 
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/labels"
@@ -20,11 +21,27 @@ type Lister interface {
 	ByNamespace(namespace string) NamespaceLister
 }
 
+// ScopeResolver is implemented by listers that know whether resources are namespaced.
+type ScopeResolver interface {
+	// IsNamespaced returns true if a group/version/kind is namespaced. It returns an
+	// ErrUnknownResource error if the scope is not known.
+	IsNamespaced(gvk schema.GroupVersionKind) (bool, error)
+}
+
+// KindLister is implemented by listers that know which kinds they can list.
+type KindLister interface {
+	// Kinds returns the group/version/kinds the lister can list.
+	Kinds() ([]schema.GroupVersionKind, error)
+}
+
 type lister struct {
 	informerManager *InformerManager
 }
 
 var _ Lister = &lister{}
+var _ SkippedResourceReporter = &lister{}
+var _ ScopeResolver = &lister{}
+var _ KindLister = &lister{}
 
 func newLister(informerManager *InformerManager) *lister {
 	l := &lister{
@@ -34,26 +51,36 @@ func newLister(informerManager *InformerManager) *lister {
 }
 
 func (l *lister) List(gvk schema.GroupVersionKind, selector labels.Selector) ([]*unstructured.Unstructured, error) {
-	informer, err := fetchInformer(l.informerManager, gvk)
+	informers, err := l.informerManager.informers(gvk, "")
 	if err != nil {
 		return nil, fmt.Errorf("get informer for %s: %w", gvk, err)
 	}
 
-	list, err := informer.Lister().List(selector)
-	if err != nil {
-		return nil, err
+	var out []*unstructured.Unstructured
+	for _, informer := range informers {
+		list, err := informer.Lister().List(selector)
+		if err != nil {
+			return nil, err
+		}
+
+		objects, err := toUnstructuredSlice(list)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, objects...)
 	}
 
-	return toUnstructuredSlice(list)
+	return out, nil
 }
 
 func (l *lister) Get(gvk schema.GroupVersionKind, name string) (*unstructured.Unstructured, error) {
-	informer, err := fetchInformer(l.informerManager, gvk)
+	informers, err := l.informerManager.informers(gvk, "")
 	if err != nil {
 		return nil, err
 	}
 
-	item, err := informer.Lister().Get(name)
+	// cluster scoped resources are only read across namespaces, so there is one informer.
+	item, err := informers[0].Lister().Get(name)
 	if err != nil {
 		return nil, err
 	}
@@ -61,6 +88,21 @@ func (l *lister) Get(gvk schema.GroupVersionKind, name string) (*unstructured.Un
 	return toUnstructured(item)
 }
 
+// SkippedResources returns the resources the informer manager skipped.
+func (l *lister) SkippedResources() ([]SkippedResource, error) {
+	return l.informerManager.SkippedResources(), nil
+}
+
+// IsNamespaced returns true if a group/version/kind is namespaced.
+func (l *lister) IsNamespaced(gvk schema.GroupVersionKind) (bool, error) {
+	return l.informerManager.IsNamespaced(gvk)
+}
+
+// Kinds returns the group/version/kinds with informers.
+func (l *lister) Kinds() ([]schema.GroupVersionKind, error) {
+	return l.informerManager.Kinds(), nil
+}
+
 func (l *lister) ByNamespace(namespace string) NamespaceLister {
 	return newNamespaceLister(l.informerManager, namespace)
 }
@@ -89,7 +131,7 @@ func newNamespaceLister(informerManager *InformerManager, namespace string) *nam
 }
 
 func (n *namespaceLister) List(gvk schema.GroupVersionKind, selector labels.Selector) (ret []*unstructured.Unstructured, err error) {
-	informer, err := fetchInformer(n.informerManager, gvk)
+	informer, err := n.informer(gvk)
 	if err != nil {
 		return nil, err
 	}
@@ -103,7 +145,7 @@ func (n *namespaceLister) List(gvk schema.GroupVersionKind, selector labels.Sele
 }
 
 func (n *namespaceLister) Get(gvk schema.GroupVersionKind, name string) (*unstructured.Unstructured, error) {
-	informer, err := fetchInformer(n.informerManager, gvk)
+	informer, err := n.informer(gvk)
 	if err != nil {
 		return nil, err
 	}
@@ -116,6 +158,74 @@ func (n *namespaceLister) Get(gvk schema.GroupVersionKind, name string) (*unstru
 	return toUnstructured(item)
 }
 
+// getObject gets an object using the cluster wide lister for cluster scoped objects and a
+// namespace lister for namespaced objects. The namespace is ignored for kinds the lister knows
+// are cluster scoped, so references from namespaced objects can be looked up with the
+// referrer's namespace.
+func getObject(lister Lister, gvk schema.GroupVersionKind, namespace, name string) (*unstructured.Unstructured, error) {
+	namespace, err := scopedNamespace(lister, gvk, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	if namespace == "" {
+		return lister.Get(gvk, name)
+	}
+
+	return lister.ByNamespace(namespace).Get(gvk, name)
+}
+
+// servedKind returns the version of a kind the lister serves. The kind is returned unchanged if the
+// lister doesn't know its kinds or doesn't serve the kind's group and kind.
+func servedKind(lister Lister, gvk schema.GroupVersionKind) (schema.GroupVersionKind, error) {
+	kindLister, ok := lister.(KindLister)
+	if !ok {
+		return gvk, nil
+	}
+
+	kinds, err := kindLister.Kinds()
+	if err != nil {
+		return schema.GroupVersionKind{}, fmt.Errorf("list kinds: %w", err)
+	}
+
+	for _, kind := range kinds {
+		if kind == gvk {
+			return gvk, nil
+		}
+	}
+
+	for _, kind := range kinds {
+		if kind.GroupKind() == gvk.GroupKind() {
+			return kind, nil
+		}
+	}
+
+	return gvk, nil
+}
+
+// scopedNamespace returns the namespace to look up a kind in. It is empty if the lister knows
+// the kind is cluster scoped, otherwise it is namespace.
+func scopedNamespace(lister Lister, gvk schema.GroupVersionKind, namespace string) (string, error) {
+	resolver, ok := lister.(ScopeResolver)
+	if !ok || namespace == "" {
+		return namespace, nil
+	}
+
+	namespaced, err := resolver.IsNamespaced(gvk)
+	if err != nil {
+		if isUnknownResource(err) {
+			return namespace, nil
+		}
+		return "", err
+	}
+
+	if !namespaced {
+		return "", nil
+	}
+
+	return namespace, nil
+}
+
 func toUnstructuredSlice(in []runtime.Object) ([]*unstructured.Unstructured, error) {
 	var out []*unstructured.Unstructured
 
@@ -139,12 +249,24 @@ func toUnstructured(in runtime.Object) (*unstructured.Unstructured, error) {
 	return object, nil
 }
 
-func fetchInformer(informerManager *InformerManager, gvk schema.GroupVersionKind) (informers.GenericInformer, error) {
-	resource, err := informerManager.Resource(gvk)
+func (n *namespaceLister) informer(gvk schema.GroupVersionKind) (informers.GenericInformer, error) {
+	informers, err := n.informerManager.informers(gvk, n.namespace)
 	if err != nil {
 		return nil, err
 	}
+	return informers[0], nil
+}
+
+// sortedKinds returns the keys of a group/version/kind map sorted.
+func sortedKinds(m map[schema.GroupVersionKind]bool) []schema.GroupVersionKind {
+	var out []schema.GroupVersionKind
+	for gvk := range m {
+		out = append(out, gvk)
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].String() < out[j].String()
+	})
 
-	informer := informerManager.factory.ForResource(resource)
-	return informer, nil
+	return out
 }
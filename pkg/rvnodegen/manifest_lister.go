@@ -0,0 +1,499 @@
+package rvnodegen
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/json"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/utils/pointer"
+)
+
+// manifestClusterScopedGroupKinds are the built in cluster scoped kinds. Custom resources are
+// cluster scoped if their definition in the manifests says so. Without a cluster to ask, any other
+// kind is assumed to be namespaced.
+var manifestClusterScopedGroupKinds = map[schema.GroupKind]bool{
+	clusterRoleGVK.GroupKind():        true,
+	clusterRoleBindingGVK.GroupKind(): true,
+	crdGVK.GroupKind():                true,
+	ingressClassGVK.GroupKind():       true,
+	namespaceGVK.GroupKind():          true,
+	nodeGVK.GroupKind():               true,
+	persistentVolumeGVK.GroupKind():   true,
+	storageClassGVK.GroupKind():       true,
+
+	{Group: "admissionregistration.k8s.io", Kind: "MutatingWebhookConfiguration"}:   true,
+	{Group: "admissionregistration.k8s.io", Kind: "ValidatingWebhookConfiguration"}: true,
+	{Group: "apiregistration.k8s.io", Kind: "APIService"}:                           true,
+	{Group: "certificates.k8s.io", Kind: "CertificateSigningRequest"}:               true,
+	{Group: "flowcontrol.apiserver.k8s.io", Kind: "FlowSchema"}:                     true,
+	{Group: "flowcontrol.apiserver.k8s.io", Kind: "PriorityLevelConfiguration"}:     true,
+	{Group: "node.k8s.io", Kind: "RuntimeClass"}:                                    true,
+	{Group: "policy", Kind: "PodSecurityPolicy"}:                                    true,
+	{Group: "scheduling.k8s.io", Kind: "PriorityClass"}:                             true,
+	{Group: "storage.k8s.io", Kind: "CSIDriver"}:                                    true,
+	{Group: "storage.k8s.io", Kind: "CSINode"}:                                      true,
+	{Group: "storage.k8s.io", Kind: "VolumeAttachment"}:                             true,
+}
+
+// ManifestLister is a Lister backed by objects read from manifests rather than a cluster. Objects
+// are matched by group and kind, so manifests may use any version of a kind.
+//
+// Manifests describe what should exist, not what a cluster creates from them, so the lister fills
+// in what a cluster would: UIDs, the namespaces objects are in, owner reference UIDs, and the
+// replica sets, jobs and pods controllers create. Created pods are pending.
+type ManifestLister struct {
+	objects       map[schema.GroupKind][]*unstructured.Unstructured
+	clusterScoped map[schema.GroupKind]bool
+}
+
+var _ Lister = &ManifestLister{}
+var _ ScopeResolver = &ManifestLister{}
+var _ KindLister = &ManifestLister{}
+
+// NewManifestLister creates an instance of ManifestLister. Objects without a namespace are placed
+// in the default namespace unless they are cluster scoped. Kinds are cluster scoped if they are
+// built in cluster scoped kinds or defined as cluster scoped by a CustomResourceDefinition in the
+// manifests. Cluster scoped custom resources need their definition in the manifests, otherwise
+// they are placed in the default namespace.
+func NewManifestLister(objects ...*unstructured.Unstructured) (*ManifestLister, error) {
+	l := &ManifestLister{
+		objects:       map[schema.GroupKind][]*unstructured.Unstructured{},
+		clusterScoped: map[schema.GroupKind]bool{},
+	}
+
+	expanded, err := expandManifestLists(objects)
+	if err != nil {
+		return nil, err
+	}
+
+	clusterScoped := l.clusterScoped
+	for groupKind := range manifestClusterScopedGroupKinds {
+		clusterScoped[groupKind] = true
+	}
+	for _, object := range expanded {
+		if object.GroupVersionKind().GroupKind() != crdGVK.GroupKind() {
+			continue
+		}
+
+		group, _, _ := unstructured.NestedString(object.Object, "spec", "group")
+		kind, _, _ := unstructured.NestedString(object.Object, "spec", "names", "kind")
+		scope, _, _ := unstructured.NestedString(object.Object, "spec", "scope")
+		if scope == "Cluster" {
+			clusterScoped[schema.GroupKind{Group: group, Kind: kind}] = true
+		}
+	}
+
+	for _, object := range expanded {
+		object = object.DeepCopy()
+
+		if object.GetName() == "" {
+			return nil, fmt.Errorf("%s without a name", object.GroupVersionKind())
+		}
+
+		if clusterScoped[object.GroupVersionKind().GroupKind()] {
+			object.SetNamespace("")
+		} else if object.GetNamespace() == "" {
+			object.SetNamespace(DefaultNamespace)
+		}
+
+		l.add(object)
+	}
+
+	for _, object := range l.all() {
+		if err := l.synthesizeControlled(object); err != nil {
+			return nil, fmt.Errorf("synthesize objects for %s %s: %w", object.GetKind(), object.GetName(), err)
+		}
+	}
+
+	for _, object := range l.all() {
+		l.resolveOwnerReferences(object)
+
+		if namespace := object.GetNamespace(); namespace != "" {
+			if _, err := l.Get(namespaceGVK, namespace); kerrors.IsNotFound(err) {
+				ns := &unstructured.Unstructured{}
+				ns.SetGroupVersionKind(namespaceGVK)
+				ns.SetName(namespace)
+				l.add(ns)
+			}
+		}
+	}
+
+	return l, nil
+}
+
+// List lists objects across namespaces.
+func (l *ManifestLister) List(gvk schema.GroupVersionKind, selector labels.Selector) ([]*unstructured.Unstructured, error) {
+	return l.list(gvk, selector, func(*unstructured.Unstructured) bool { return true }), nil
+}
+
+// Get gets a cluster scoped object.
+func (l *ManifestLister) Get(gvk schema.GroupVersionKind, name string) (*unstructured.Unstructured, error) {
+	return l.get(gvk, "", name)
+}
+
+// IsNamespaced returns true if a kind is namespaced. Kinds are namespaced unless they are built
+// in cluster scoped kinds or custom resources defined as cluster scoped.
+func (l *ManifestLister) IsNamespaced(gvk schema.GroupVersionKind) (bool, error) {
+	return !l.clusterScoped[gvk.GroupKind()], nil
+}
+
+// Kinds returns the kinds in the manifests, at the version of their first object.
+func (l *ManifestLister) Kinds() ([]schema.GroupVersionKind, error) {
+	kinds := map[schema.GroupVersionKind]bool{}
+	for _, objects := range l.objects {
+		if len(objects) > 0 {
+			kinds[objects[0].GroupVersionKind()] = true
+		}
+	}
+	return sortedKinds(kinds), nil
+}
+
+// ByNamespace returns a lister for a namespace.
+func (l *ManifestLister) ByNamespace(namespace string) NamespaceLister {
+	return &manifestNamespaceLister{
+		lister:    l,
+		namespace: namespace,
+	}
+}
+
+func (l *ManifestLister) list(gvk schema.GroupVersionKind, selector labels.Selector, include func(*unstructured.Unstructured) bool) []*unstructured.Unstructured {
+	var out []*unstructured.Unstructured
+	for _, object := range l.objects[gvk.GroupKind()] {
+		if include(object) && selector.Matches(labels.Set(object.GetLabels())) {
+			out = append(out, asVersion(object, gvk))
+		}
+	}
+	return out
+}
+
+func (l *ManifestLister) get(gvk schema.GroupVersionKind, namespace, name string) (*unstructured.Unstructured, error) {
+	for _, object := range l.objects[gvk.GroupKind()] {
+		if object.GetNamespace() == namespace && object.GetName() == name {
+			return asVersion(object, gvk), nil
+		}
+	}
+
+	resource := schema.GroupResource{Group: gvk.Group, Resource: strings.ToLower(gvk.Kind)}
+	return nil, kerrors.NewNotFound(resource, name)
+}
+
+func (l *ManifestLister) add(object *unstructured.Unstructured) {
+	if object.GetUID() == "" {
+		object.SetUID(manifestUID(object.GroupVersionKind().GroupKind(), object.GetNamespace(), object.GetName()))
+	}
+
+	groupKind := object.GroupVersionKind().GroupKind()
+	l.objects[groupKind] = append(l.objects[groupKind], object)
+}
+
+func (l *ManifestLister) all() []*unstructured.Unstructured {
+	var out []*unstructured.Unstructured
+	for _, objects := range l.objects {
+		out = append(out, objects...)
+	}
+	return out
+}
+
+// synthesizeControlled adds the objects a controller would create for object, unless the
+// manifests already include objects it controls.
+func (l *ManifestLister) synthesizeControlled(object *unstructured.Unstructured) error {
+	groupKind := object.GroupVersionKind().GroupKind()
+	if groupKind != deploymentGVK.GroupKind() && groupKind != replicaSetGVK.GroupKind() &&
+		groupKind != replicationControllerGVK.GroupKind() && groupKind != statefulSetGVK.GroupKind() &&
+		groupKind != daemonSetGVK.GroupKind() && groupKind != jobGVK.GroupKind() {
+		return nil
+	}
+
+	for _, objects := range l.objects {
+		for _, candidate := range objects {
+			if controller := metav1.GetControllerOf(candidate); controller != nil &&
+				controller.Kind == object.GetKind() && controller.Name == object.GetName() &&
+				candidate.GetNamespace() == object.GetNamespace() {
+				return nil
+			}
+		}
+	}
+
+	template, _, err := unstructured.NestedMap(object.Object, "spec", "template")
+	if err != nil {
+		return fmt.Errorf("read pod template: %w", err)
+	}
+
+	replicas, found, err := unstructured.NestedInt64(object.Object, "spec", "replicas")
+	if err != nil {
+		return fmt.Errorf("read replicas: %w", err)
+	}
+	if !found {
+		replicas = 1
+	}
+
+	switch groupKind {
+	case deploymentGVK.GroupKind():
+		replicaSet := &unstructured.Unstructured{Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"replicas": replicas,
+				"template": template,
+			},
+		}}
+		replicaSet.SetGroupVersionKind(replicaSetGVK)
+		replicaSet.SetName(object.GetName() + "-" + manifestSuffix(object.GetName(), 10))
+		replicaSet.SetNamespace(object.GetNamespace())
+		replicaSet.SetLabels(templateLabels(template))
+		replicaSet.SetAnnotations(map[string]string{deploymentRevisionAnnotation: "1"})
+		replicaSet.SetOwnerReferences([]metav1.OwnerReference{manifestOwnerReference(object)})
+		l.add(replicaSet)
+
+		return l.synthesizeControlled(replicaSet)
+	case statefulSetGVK.GroupKind():
+		for i := int64(0); i < replicas; i++ {
+			l.add(manifestPod(object, template, fmt.Sprintf("%s-%d", object.GetName(), i)))
+		}
+	case daemonSetGVK.GroupKind(), jobGVK.GroupKind():
+		l.add(manifestPod(object, template, object.GetName()+"-"+manifestSuffix(object.GetName(), 5)))
+	default:
+		for i := int64(0); i < replicas; i++ {
+			name := fmt.Sprintf("%s-%d", object.GetName(), i)
+			l.add(manifestPod(object, template, object.GetName()+"-"+manifestSuffix(name, 5)))
+		}
+	}
+
+	return nil
+}
+
+// resolveOwnerReferences fills in the UIDs of owner references without them.
+func (l *ManifestLister) resolveOwnerReferences(object *unstructured.Unstructured) {
+	refs := object.GetOwnerReferences()
+	for i := range refs {
+		if refs[i].UID != "" {
+			continue
+		}
+
+		gv, err := schema.ParseGroupVersion(refs[i].APIVersion)
+		if err != nil {
+			continue
+		}
+
+		namespace := object.GetNamespace()
+		if l.clusterScoped[gv.WithKind(refs[i].Kind).GroupKind()] {
+			namespace = ""
+		}
+
+		if owner, err := l.get(gv.WithKind(refs[i].Kind), namespace, refs[i].Name); err == nil {
+			refs[i].UID = owner.GetUID()
+			continue
+		}
+
+		refs[i].UID = manifestUID(gv.WithKind(refs[i].Kind).GroupKind(), namespace, refs[i].Name)
+	}
+
+	if len(refs) > 0 {
+		object.SetOwnerReferences(refs)
+	}
+}
+
+type manifestNamespaceLister struct {
+	lister    *ManifestLister
+	namespace string
+}
+
+var _ NamespaceLister = &manifestNamespaceLister{}
+
+func (n *manifestNamespaceLister) List(gvk schema.GroupVersionKind, selector labels.Selector) ([]*unstructured.Unstructured, error) {
+	return n.lister.list(gvk, selector, func(object *unstructured.Unstructured) bool {
+		return object.GetNamespace() == n.namespace
+	}), nil
+}
+
+func (n *manifestNamespaceLister) Get(gvk schema.GroupVersionKind, name string) (*unstructured.Unstructured, error) {
+	return n.lister.get(gvk, n.namespace, name)
+}
+
+// ReadManifests reads objects from manifest files. Directories are read recursively for .yaml,
+// .yml and .json files, and "-" reads from stdin.
+func ReadManifests(paths ...string) ([]*unstructured.Unstructured, error) {
+	var objects []*unstructured.Unstructured
+
+	for _, path := range paths {
+		if path == "-" {
+			decoded, err := DecodeManifests(os.Stdin)
+			if err != nil {
+				return nil, fmt.Errorf("read stdin: %w", err)
+			}
+			objects = append(objects, decoded...)
+			continue
+		}
+
+		err := filepath.Walk(path, func(name string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if info.IsDir() {
+				return nil
+			}
+
+			switch strings.ToLower(filepath.Ext(name)) {
+			case ".yaml", ".yml", ".json":
+			default:
+				if name != path {
+					return nil
+				}
+			}
+
+			f, err := os.Open(name)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			decoded, err := DecodeManifests(f)
+			if err != nil {
+				return fmt.Errorf("read %s: %w", name, err)
+			}
+			objects = append(objects, decoded...)
+
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return objects, nil
+}
+
+// DecodeManifests decodes a stream of YAML documents or JSON objects.
+func DecodeManifests(r io.Reader) ([]*unstructured.Unstructured, error) {
+	var objects []*unstructured.Unstructured
+
+	decoder := yaml.NewYAMLOrJSONDecoder(r, 4096)
+	for {
+		var raw runtime.RawExtension
+		if err := decoder.Decode(&raw); err != nil {
+			if err == io.EOF {
+				return objects, nil
+			}
+			return nil, fmt.Errorf("decode manifest: %w", err)
+		}
+
+		// integers are decoded as int64 rather than float64, as they are when read from a cluster.
+		var m map[string]interface{}
+		if err := json.Unmarshal(raw.Raw, &m); err != nil {
+			return nil, fmt.Errorf("decode manifest: %w", err)
+		}
+
+		if len(m) == 0 {
+			continue
+		}
+
+		object := &unstructured.Unstructured{Object: m}
+		if object.GetKind() == "" {
+			return nil, fmt.Errorf("manifest %q has no kind", object.GetName())
+		}
+
+		objects = append(objects, object)
+	}
+}
+
+// expandManifestLists replaces List kinds with their items.
+func expandManifestLists(objects []*unstructured.Unstructured) ([]*unstructured.Unstructured, error) {
+	var out []*unstructured.Unstructured
+
+	for _, object := range objects {
+		if !object.IsList() {
+			out = append(out, object)
+			continue
+		}
+
+		list, err := object.ToList()
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", object.GetKind(), err)
+		}
+
+		for i := range list.Items {
+			items, err := expandManifestLists([]*unstructured.Unstructured{&list.Items[i]})
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, items...)
+		}
+	}
+
+	return out, nil
+}
+
+// asVersion returns object as gvk's version. Only the API version changes.
+func asVersion(object *unstructured.Unstructured, gvk schema.GroupVersionKind) *unstructured.Unstructured {
+	if object.GroupVersionKind() == gvk {
+		return object
+	}
+
+	object = object.DeepCopy()
+	object.SetGroupVersionKind(gvk)
+	return object
+}
+
+func manifestPod(owner *unstructured.Unstructured, template map[string]interface{}, name string) *unstructured.Unstructured {
+	spec, _, _ := unstructured.NestedMap(template, "spec")
+	if serviceAccountName, _, _ := unstructured.NestedString(spec, "serviceAccountName"); serviceAccountName != "" {
+		spec["serviceAccount"] = serviceAccountName
+	}
+
+	pod := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec":   spec,
+		"status": map[string]interface{}{"phase": "Pending"},
+	}}
+	pod.SetGroupVersionKind(podGVK)
+	pod.SetName(name)
+	pod.SetNamespace(owner.GetNamespace())
+	pod.SetLabels(templateLabels(template))
+	pod.SetOwnerReferences([]metav1.OwnerReference{manifestOwnerReference(owner)})
+
+	return pod
+}
+
+func manifestOwnerReference(owner *unstructured.Unstructured) metav1.OwnerReference {
+	return metav1.OwnerReference{
+		APIVersion:         owner.GetAPIVersion(),
+		Kind:               owner.GetKind(),
+		Name:               owner.GetName(),
+		UID:                owner.GetUID(),
+		Controller:         pointer.BoolPtr(true),
+		BlockOwnerDeletion: pointer.BoolPtr(true),
+	}
+}
+
+func templateLabels(template map[string]interface{}) map[string]string {
+	labels, _, _ := unstructured.NestedStringMap(template, "metadata", "labels")
+	return labels
+}
+
+// manifestUID is a UID derived from an object's identity, so it is stable across reads.
+func manifestUID(groupKind schema.GroupKind, namespace, name string) types.UID {
+	sum := sha1.Sum([]byte(groupKind.String() + "/" + namespace + "/" + name))
+	return types.UID(fmt.Sprintf("%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16]))
+}
+
+// manifestSuffix is a stable generated name suffix.
+func manifestSuffix(seed string, n int) string {
+	const alphabet = "bcdfghjklmnpqrstvwxz2456789"
+
+	sum := sha1.Sum([]byte(seed))
+	suffix := make([]byte, n)
+	for i := range suffix {
+		suffix[i] = alphabet[int(sum[i])%len(alphabet)]
+	}
+	return string(suffix)
+}
@@ -0,0 +1,179 @@
+package rvnodegen
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/pointer"
+)
+
+func TestNewManifestLister_synthesizesControlled(t *testing.T) {
+	deployment := newTestObject(deploymentGVK, "", "web")
+	deployment.Object["spec"] = map[string]interface{}{
+		"replicas": int64(2),
+		"template": map[string]interface{}{
+			"metadata": map[string]interface{}{"labels": map[string]interface{}{"app": "web"}},
+			"spec":     map[string]interface{}{"serviceAccountName": "web"},
+		},
+	}
+
+	// the manifests include the job's pod, so no pod is created for it.
+	job := newTestObject(jobGVK, "default", "migrate")
+	jobPod := newTestObject(podGVK, "default", "migrate-1")
+	jobPod.SetOwnerReferences([]metav1.OwnerReference{{APIVersion: "batch/v1", Kind: "Job", Name: "migrate", Controller: pointer.BoolPtr(true)}})
+
+	lister, err := NewManifestLister(deployment, job, jobPod)
+	if err != nil {
+		t.Fatalf("NewManifestLister() error = %v", err)
+	}
+
+	listed, err := lister.ByNamespace("default").Get(deploymentGVK, "web")
+	if err != nil {
+		t.Fatalf("get deployment: %v", err)
+	}
+
+	replicaSets, _ := lister.ByNamespace("default").List(replicaSetGVK, labels.Everything())
+	if len(replicaSets) != 1 {
+		t.Fatalf("replica sets = %d, want 1", len(replicaSets))
+	}
+	if replicaSets[0].GetUID() == "" {
+		t.Error("replica set uid was not set")
+	}
+	if controller := metav1.GetControllerOf(replicaSets[0]); controller == nil || controller.UID != listed.GetUID() {
+		t.Errorf("replica set controller = %v, want deployment %s", controller, listed.GetUID())
+	}
+
+	pods, _ := lister.ByNamespace("default").List(podGVK, labels.SelectorFromSet(labels.Set{"app": "web"}))
+	if len(pods) != 2 {
+		t.Fatalf("pods = %d, want 2", len(pods))
+	}
+	for _, pod := range pods {
+		if controller := metav1.GetControllerOf(pod); controller == nil || controller.UID != replicaSets[0].GetUID() {
+			t.Errorf("pod %s controller = %v, want replica set", pod.GetName(), controller)
+		}
+		if phase, _, _ := unstructured.NestedString(pod.Object, "status", "phase"); phase != "Pending" {
+			t.Errorf("pod %s phase = %q, want Pending", pod.GetName(), phase)
+		}
+		if serviceAccount, _, _ := unstructured.NestedString(pod.Object, "spec", "serviceAccount"); serviceAccount != "web" {
+			t.Errorf("pod %s service account = %q, want web", pod.GetName(), serviceAccount)
+		}
+	}
+
+	jobPods, _ := lister.ByNamespace("default").List(podGVK, labels.Everything())
+	if len(jobPods) != 3 {
+		t.Errorf("pods = %d, want the deployment's 2 and the job's 1", len(jobPods))
+	}
+
+	listedJob, _ := lister.ByNamespace("default").Get(jobGVK, "migrate")
+	listedJobPod, _ := lister.ByNamespace("default").Get(podGVK, "migrate-1")
+	if controller := metav1.GetControllerOf(listedJobPod); controller == nil || controller.UID != listedJob.GetUID() {
+		t.Errorf("job pod controller = %v, want job %s", controller, listedJob.GetUID())
+	}
+
+	if _, err := lister.Get(namespaceGVK, "default"); err != nil {
+		t.Errorf("default namespace was not created: %v", err)
+	}
+}
+
+func TestNewManifestLister_ownerReferences(t *testing.T) {
+	// owners missing from the manifests get the uid they would have if they were included.
+	pod := newTestObject(podGVK, "default", "orphaned")
+	pod.SetOwnerReferences([]metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "gone"}})
+
+	lister, err := NewManifestLister(pod)
+	if err != nil {
+		t.Fatalf("NewManifestLister() error = %v", err)
+	}
+
+	listed, _ := lister.ByNamespace("default").Get(podGVK, "orphaned")
+	want := manifestUID(replicaSetGVK.GroupKind(), "default", "gone")
+	if got := listed.GetOwnerReferences()[0].UID; got != want {
+		t.Errorf("owner uid = %q, want %q", got, want)
+	}
+
+	// uids in the manifests are kept.
+	configMap := newTestObject(configMapGVK, "default", "config")
+	lister, err = NewManifestLister(configMap)
+	if err != nil {
+		t.Fatalf("NewManifestLister() error = %v", err)
+	}
+	listed, _ = lister.ByNamespace("default").Get(configMapGVK, "config")
+	if listed.GetUID() != configMap.GetUID() {
+		t.Errorf("uid = %q, want %q", listed.GetUID(), configMap.GetUID())
+	}
+}
+
+func TestNewManifestLister_lists(t *testing.T) {
+	nested := &unstructured.UnstructuredList{}
+	nested.SetAPIVersion("v1")
+	nested.SetKind("List")
+	nested.Items = []unstructured.Unstructured{*newTestObject(secretGVK, "default", "credentials")}
+
+	list := &unstructured.UnstructuredList{}
+	list.SetAPIVersion("v1")
+	list.SetKind("List")
+	list.Items = []unstructured.Unstructured{
+		*newTestObject(configMapGVK, "default", "config"),
+		{Object: nested.UnstructuredContent()},
+	}
+
+	lister, err := NewManifestLister(&unstructured.Unstructured{Object: list.UnstructuredContent()})
+	if err != nil {
+		t.Fatalf("NewManifestLister() error = %v", err)
+	}
+
+	if _, err := lister.ByNamespace("default").Get(configMapGVK, "config"); err != nil {
+		t.Errorf("list item was not read: %v", err)
+	}
+	if _, err := lister.ByNamespace("default").Get(secretGVK, "credentials"); err != nil {
+		t.Errorf("nested list item was not read: %v", err)
+	}
+}
+
+func TestNewManifestLister_customResourceScope(t *testing.T) {
+	clusterResource := fakeResource{gvk: schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Cluster"}, resource: "clusters"}
+	namespacedResource := fakeResource{gvk: schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Widget"}, resource: "widgets", namespaced: true}
+	undefined := schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Gadget"}
+
+	lister, err := NewManifestLister(
+		newTestCRD(clusterResource),
+		newTestCRD(namespacedResource),
+		newTestObject(clusterResource.gvk, "other", "main"),
+		newTestObject(namespacedResource.gvk, "", "widget"),
+		newTestObject(undefined, "", "gadget"),
+	)
+	if err != nil {
+		t.Fatalf("NewManifestLister() error = %v", err)
+	}
+
+	tests := []struct {
+		gvk        schema.GroupVersionKind
+		name       string
+		namespace  string
+		namespaced bool
+	}{
+		{gvk: clusterResource.gvk, name: "main", namespace: "", namespaced: false},
+		{gvk: namespacedResource.gvk, name: "widget", namespace: DefaultNamespace, namespaced: true},
+		{gvk: undefined, name: "gadget", namespace: DefaultNamespace, namespaced: true},
+		{gvk: crdGVK, name: "clusters.example.com", namespace: "", namespaced: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.gvk.Kind, func(t *testing.T) {
+			namespaced, err := lister.IsNamespaced(test.gvk)
+			if err != nil {
+				t.Fatalf("IsNamespaced() error = %v", err)
+			}
+			if namespaced != test.namespaced {
+				t.Errorf("IsNamespaced() = %t, want %t", namespaced, test.namespaced)
+			}
+
+			if _, err := lister.ByNamespace(test.namespace).Get(test.gvk, test.name); err != nil {
+				t.Errorf("get %s in namespace %q: %v", test.name, test.namespace, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package rvnodegen
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var mermaidUnsafeRunes = regexp.MustCompile(`[^A-Za-z0-9_]+`)
+
+// MermaidGraphEncoder encodes nodes as a Mermaid flowchart. Groups are rendered as subgraphs,
+// and classes style nodes by health and node type. Identifiers are derived from kind, namespace
+// and name, so they stay the same across builds.
+type MermaidGraphEncoder struct{}
+
+var _ GraphEncoder = &MermaidGraphEncoder{}
+
+// Format is the name of the format.
+func (e *MermaidGraphEncoder) Format() string {
+	return "mermaid"
+}
+
+// ContentType is the media type of the encoding.
+func (e *MermaidGraphEncoder) ContentType() string {
+	return "text/vnd.mermaid"
+}
+
+// TypedEdges returns true because edges are labeled with their relation.
+func (e *MermaidGraphEncoder) TypedEdges() bool {
+	return true
+}
+
+// MetadataFields are the metadata fields used to create identifiers.
+func (e *MermaidGraphEncoder) MetadataFields() []MetadataField {
+	return []MetadataField{MetadataFieldKind, MetadataFieldNamespace, MetadataFieldName}
+}
+
+// Encode writes the nodes to w.
+func (e *MermaidGraphEncoder) Encode(w io.Writer, nodes []GraphNode) error {
+	bw := bufio.NewWriter(w)
+
+	ids := mermaidIDs(nodes)
+
+	fmt.Fprintln(bw, "flowchart LR")
+
+	tree := newGraphTree(nodes)
+	for _, node := range tree.roots {
+		writeMermaidNode(bw, tree, ids, node, "  ")
+	}
+
+	for _, edge := range graphEdges(nodes) {
+		if edge.Relation == "" {
+			fmt.Fprintf(bw, "  %s --> %s\n", ids[edge.Source], ids[edge.Target])
+			continue
+		}
+		fmt.Fprintf(bw, "  %s -->|%s| %s\n", ids[edge.Source], edge.Relation, ids[edge.Target])
+	}
+
+	for _, status := range []HealthStatusType{HealthStatusTypeHealthy, HealthStatusTypeDegraded,
+		HealthStatusTypeFailure, HealthStatusTypeMissing, ""} {
+		colors := colorsForHealth(status)
+		fmt.Fprintf(bw, "  classDef %s fill:%s,stroke:%s\n", mermaidHealthClass(status), colors.fill, colors.stroke)
+	}
+
+	for _, nodeType := range []NodeType{NodeTypeWorkload, NodeTypeNetworking, NodeTypeConfiguration,
+		NodeTypeCustomResource, NodeTypeStorage, NodeTypeInfrastructure, NodeTypeGitOps} {
+		fmt.Fprintf(bw, "  classDef %s %s\n", mermaidTypeClass(nodeType), mermaidTypeStyle(nodeType))
+	}
+
+	for _, node := range nodes {
+		if node.IsGroup != nil || len(tree.children[node.ID]) > 0 {
+			continue
+		}
+		fmt.Fprintf(bw, "  class %s %s\n", ids[node.ID], mermaidHealthClass(node.HealthStatus))
+		if node.NodeType != "" {
+			fmt.Fprintf(bw, "  class %s %s\n", ids[node.ID], mermaidTypeClass(node.NodeType))
+		}
+	}
+
+	return bw.Flush()
+}
+
+// writeMermaidNode writes a node. Groups are written as subgraphs containing their members.
+func writeMermaidNode(w io.Writer, tree graphTree, ids map[string]string, node GraphNode, indent string) {
+	children := tree.children[node.ID]
+	if node.IsGroup == nil && len(children) == 0 {
+		fmt.Fprintf(w, "%s%s\n", indent, mermaidNodeShape(ids[node.ID], node))
+		return
+	}
+
+	fmt.Fprintf(w, "%ssubgraph %s[%s]\n", indent, ids[node.ID], mermaidLabel(node.Label))
+	for _, child := range children {
+		writeMermaidNode(w, tree, ids, child, indent+"  ")
+	}
+	fmt.Fprintf(w, "%send\n", indent)
+}
+
+// mermaidIDs creates identifiers for nodes. Object nodes use their kind, namespace and name and
+// other nodes use their id. Collisions are resolved with a numeric suffix. Suffixes are assigned
+// in node id order, so they don't depend on the order of the nodes.
+func mermaidIDs(nodes []GraphNode) map[string]string {
+	ids := map[string]string{}
+	used := map[string]bool{}
+
+	sorted := append([]GraphNode(nil), nodes...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].ID < sorted[j].ID
+	})
+
+	for _, node := range sorted {
+		base := node.ID
+		if m := node.Metadata; m != nil && m.Kind != "" && m.Name != "" {
+			base = strings.Join([]string{m.Kind, m.Namespace, m.Name}, "_")
+		}
+
+		base = "n_" + strings.Trim(mermaidUnsafeRunes.ReplaceAllString(base, "_"), "_")
+
+		id := base
+		for i := 2; used[id]; i++ {
+			id = fmt.Sprintf("%s_%d", base, i)
+		}
+
+		used[id] = true
+		ids[node.ID] = id
+	}
+
+	return ids
+}
+
+func mermaidNodeShape(id string, node GraphNode) string {
+	label := mermaidLabel(node.Label)
+
+	switch node.NodeType {
+	case NodeTypeNetworking:
+		return fmt.Sprintf("%s([%s])", id, label)
+	case NodeTypeConfiguration:
+		return fmt.Sprintf("%s[/%s/]", id, label)
+	case NodeTypeStorage:
+		return fmt.Sprintf("%s[(%s)]", id, label)
+	case NodeTypeCustomResource, NodeTypeGitOps:
+		return fmt.Sprintf("%s{{%s}}", id, label)
+	default:
+		return fmt.Sprintf("%s[%s]", id, label)
+	}
+}
+
+// mermaidLabel quotes a label. Quotes in the label are escaped as entities.
+func mermaidLabel(label string) string {
+	return `"` + strings.ReplaceAll(label, `"`, "#quot;") + `"`
+}
+
+func mermaidHealthClass(status HealthStatusType) string {
+	if status == "" {
+		return "health-unknown"
+	}
+	return "health-" + strings.ToLower(string(status))
+}
+
+func mermaidTypeClass(nodeType NodeType) string {
+	return "type-" + string(nodeType)
+}
+
+func mermaidTypeStyle(nodeType NodeType) string {
+	switch nodeType {
+	case NodeTypeWorkload:
+		return "stroke-width:2px"
+	case NodeTypeConfiguration, NodeTypeStorage:
+		return "stroke-width:1px"
+	case NodeTypeCustomResource, NodeTypeGitOps:
+		return "stroke-width:1px,stroke-dasharray:4 2"
+	default:
+		return "stroke-width:1px,font-style:italic"
+	}
+}
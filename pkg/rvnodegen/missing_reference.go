@@ -0,0 +1,129 @@
+package rvnodegen
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// missingNodeIDPrefix prefixes the ids of placeholder nodes for missing objects.
+	missingNodeIDPrefix = "missing:"
+	// unknownNodeIDPrefix prefixes the ids of placeholder nodes for objects whose kind is unknown.
+	unknownNodeIDPrefix = "unknown:"
+
+	// unknownResourceReason is why a placeholder for an object whose kind is unknown was emitted.
+	unknownResourceReason = "kind is not served by the cluster or can't be read"
+)
+
+// visitReference visits a referenced object. If the object does not exist, a placeholder node
+// is emitted for it instead. Objects whose kind is unknown get a placeholder with unknown
+// health, since it's not known whether they exist. The returned object is the referenced object
+// or the placeholder's object, and its uid is the id of the node to point edges at.
+func (v *Visitor) visitReference(gvk schema.GroupVersionKind, namespace, name string) (*unstructured.Unstructured, error) {
+	object, err := v.getReference(gvk, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if isPlaceholder(object) {
+		return object, nil
+	}
+
+	if err := v.Visit(false, object); err != nil {
+		return nil, err
+	}
+
+	return object, nil
+}
+
+// getReference gets a referenced object, or emits a placeholder for it if it does not exist or
+// its kind is unknown.
+func (v *Visitor) getReference(gvk schema.GroupVersionKind, namespace, name string) (*unstructured.Unstructured, error) {
+	namespace, err := scopedNamespace(v.lister, gvk, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	object, err := getObject(v.lister, gvk, namespace, name)
+	if err != nil {
+		switch {
+		case isMissing(err):
+			return v.visitPlaceholder(gvk, namespace, name, missingNodeIDPrefix, HealthStatusTypeMissing, "")
+		case isUnknownResource(err):
+			return v.visitPlaceholder(gvk, namespace, name, unknownNodeIDPrefix, HealthStatusTypeUnknown,
+				unknownResourceReason)
+		default:
+			return nil, fmt.Errorf("get %s %s: %w", gvk.Kind, name, err)
+		}
+	}
+
+	return object, nil
+}
+
+// visitPlaceholder emits a placeholder node for an object that does not exist or whose kind is
+// unknown.
+func (v *Visitor) visitPlaceholder(gvk schema.GroupVersionKind, namespace, name, prefix string, healthStatus HealthStatusType, reason string) (*unstructured.Unstructured, error) {
+	object := placeholderObject(gvk, namespace, name, prefix)
+
+	if _, ok := v.visitedCache[object.GetUID()]; ok {
+		return object, nil
+	}
+	v.visitedCache[object.GetUID()] = true
+
+	// placeholders for kinds the cluster doesn't know about are treated as custom resources.
+	nodeType, err := detectNodeType(v.lister, object)
+	if err != nil {
+		nodeType = NodeTypeCustomResource
+	}
+
+	node := GraphNode{
+		ID:           string(object.GetUID()),
+		Label:        name,
+		NodeType:     nodeType,
+		HealthStatus: healthStatus,
+		Metadata: &NodeMetadata{
+			APIVersion: object.GetAPIVersion(),
+			Kind:       object.GetKind(),
+			Name:       name,
+			Namespace:  namespace,
+			Status:     string(healthStatus),
+		},
+	}
+
+	if reason != "" {
+		node.Extra = map[string]interface{}{"reason": reason}
+	}
+
+	if err := v.emitter.Emit(object, node); err != nil {
+		return nil, fmt.Errorf("emit node: %w", err)
+	}
+
+	return object, nil
+}
+
+// placeholderObject creates the object for a placeholder node. Its uid is derived from the
+// reference, e.g. "missing:Secret:default/certs".
+func placeholderObject(gvk schema.GroupVersionKind, namespace, name, prefix string) *unstructured.Unstructured {
+	id := fmt.Sprintf("%s%s:%s", prefix, gvk.GroupKind(), name)
+	if namespace != "" {
+		id = fmt.Sprintf("%s%s:%s/%s", prefix, gvk.GroupKind(), namespace, name)
+	}
+
+	object := &unstructured.Unstructured{}
+	object.SetGroupVersionKind(gvk)
+	object.SetNamespace(namespace)
+	object.SetName(name)
+	object.SetUID(types.UID(id))
+
+	return object
+}
+
+// isPlaceholder returns true if an object is the object for a placeholder node.
+func isPlaceholder(object *unstructured.Unstructured) bool {
+	uid := string(object.GetUID())
+	return strings.HasPrefix(uid, missingNodeIDPrefix) || strings.HasPrefix(uid, unknownNodeIDPrefix)
+}
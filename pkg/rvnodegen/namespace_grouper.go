@@ -0,0 +1,100 @@
+package rvnodegen
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/utils/pointer"
+)
+
+// NamespaceGrouper groups top level nodes by namespace. Cluster scoped objects are not grouped.
+type NamespaceGrouper struct{}
+
+var _ Grouper = &NamespaceGrouper{}
+
+// NewNamespaceGrouper creates an instance of NamespaceGrouper.
+func NewNamespaceGrouper() *NamespaceGrouper {
+	return &NamespaceGrouper{}
+}
+
+// Name is the name of the grouper.
+func (g *NamespaceGrouper) Name() string {
+	return "Namespace"
+}
+
+// Group places top level nodes into namespace groups. Synthetic groups take the namespace of
+// their members.
+func (g *NamespaceGrouper) Group(nodes []GraphNode, objects map[string]*unstructured.Unstructured) ([]GraphNode, error) {
+	indexByID := map[string]int{}
+	for i := range nodes {
+		indexByID[nodes[i].ID] = i
+	}
+
+	namespaceByID := map[string]string{}
+	for i := range nodes {
+		object, ok := objects[nodes[i].ID]
+		if !ok || object.GetNamespace() == "" {
+			continue
+		}
+
+		namespaceByID[nodes[i].ID] = object.GetNamespace()
+
+		// synthetic ancestors take the namespace of their first member.
+		for parent := nodes[i].Parent; parent != nil; {
+			if _, ok := namespaceByID[*parent]; ok {
+				break
+			}
+			if _, ok := objects[*parent]; ok {
+				break
+			}
+
+			namespaceByID[*parent] = object.GetNamespace()
+
+			index, ok := indexByID[*parent]
+			if !ok {
+				break
+			}
+			parent = nodes[index].Parent
+		}
+	}
+
+	groups := map[string]*GraphNode{}
+	var groupIDs []string
+
+	for i := range nodes {
+		if nodes[i].Parent != nil {
+			continue
+		}
+
+		namespace, ok := namespaceByID[nodes[i].ID]
+		if !ok {
+			continue
+		}
+
+		id := namespaceGroupID(namespace)
+
+		group, ok := groups[id]
+		if !ok {
+			group = &GraphNode{
+				ID:           id,
+				Label:        namespace,
+				IsGroup:      pointer.StringPtr("yes"),
+				NodeType:     NodeTypeNamespace,
+				HealthStatus: HealthStatusTypeHealthy,
+			}
+			groups[id] = group
+			groupIDs = append(groupIDs, id)
+		}
+
+		group.HealthStatus = worseHealthStatus(group.HealthStatus, nodes[i].HealthStatus)
+		nodes[i].Parent = pointer.StringPtr(id)
+	}
+
+	for _, id := range groupIDs {
+		nodes = append(nodes, *groups[id])
+	}
+
+	return nodes, nil
+}
+
+func namespaceGroupID(namespace string) string {
+	return "namespace:" + namespace
+}
@@ -0,0 +1,88 @@
+package rvnodegen
+
+// neighborhood returns the seed nodes and the nodes up to a number of hops away from them. Edges
+// are followed in both directions, and groups lead to their members. The groups containing
+// returned nodes are always returned so the hierarchy remains intact. Targets and edges to nodes
+// that are not returned are removed.
+func neighborhood(nodes []GraphNode, seeds []string, hops int) []GraphNode {
+	keep := map[string]bool{}
+	for _, id := range seeds {
+		keep[id] = true
+	}
+
+	adjacency := undirectedAdjacency(nodes)
+	for _, node := range nodes {
+		if node.Parent != nil {
+			adjacency[*node.Parent] = append(adjacency[*node.Parent], node.ID)
+		}
+	}
+
+	frontier := seeds
+	for i := 0; i < hops && len(frontier) > 0; i++ {
+		var next []string
+		for _, id := range frontier {
+			for _, neighbor := range adjacency[id] {
+				if !keep[neighbor] {
+					keep[neighbor] = true
+					next = append(next, neighbor)
+				}
+			}
+		}
+		frontier = next
+	}
+
+	keepAncestors(nodes, keep)
+
+	var out []GraphNode
+	for _, node := range nodes {
+		if keep[node.ID] {
+			out = append(out, pruneEdges(node, keep))
+		}
+	}
+
+	return out
+}
+
+// pruneEdges removes a node's targets and edges to nodes that are not kept.
+func pruneEdges(node GraphNode, keep map[string]bool) GraphNode {
+	var targets []string
+	for _, target := range node.Targets {
+		if keep[target] {
+			targets = append(targets, target)
+		}
+	}
+	node.Targets = targets
+
+	var edges []Edge
+	for _, edge := range node.Edges {
+		if keep[edge.Target] {
+			edges = append(edges, edge)
+		}
+	}
+	node.Edges = edges
+
+	return node
+}
+
+// undirectedAdjacency returns the neighbors of each node using edges in both directions.
+func undirectedAdjacency(nodes []GraphNode) map[string][]string {
+	adjacency := map[string][]string{}
+	for _, node := range nodes {
+		for _, edge := range nodeEdges(node) {
+			adjacency[edge.Source] = append(adjacency[edge.Source], edge.Target)
+			adjacency[edge.Target] = append(adjacency[edge.Target], edge.Source)
+		}
+	}
+	return adjacency
+}
+
+// keepAncestors adds the groups containing kept nodes.
+func keepAncestors(nodes []GraphNode, keep map[string]bool) {
+	byID := nodesByID(nodes)
+
+	for id := range keep {
+		for node, ok := byID[id]; ok && node.Parent != nil; node, ok = byID[*node.Parent] {
+			keep[*node.Parent] = true
+		}
+	}
+}
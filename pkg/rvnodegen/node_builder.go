@@ -6,39 +6,175 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 )
 
+// DefaultNamespace is the namespace used when a scope does not have namespaces, unless the
+// DefaultScopeNamespace option sets another.
+const DefaultNamespace = "default"
+
+// Scope is the set of namespaces a build covers.
+type Scope struct {
+	// Namespaces are the namespaces to build.
+	Namespaces []string `json:"namespaces,omitempty"`
+	// AllNamespaces builds across all namespaces.
+	AllNamespaces bool `json:"allNamespaces,omitempty"`
+}
+
 // NodeBuilder builds nodes.
 type NodeBuilder struct {
-	lister Lister
+	lister  Lister
+	options []Option
 }
 
 // NewNodeBuilder creates an instance of NodeBuilder.
-func NewNodeBuilder(lister Lister) *NodeBuilder {
+func NewNodeBuilder(lister Lister, options ...Option) *NodeBuilder {
 	n := &NodeBuilder{
-		lister: lister,
+		lister:  lister,
+		options: options,
 	}
 	return n
 }
 
 // Build builds a list of nodes.
 func (n *NodeBuilder) Build(namespace string) ([]GraphNode, error) {
-	objects, err := n.lister.
-		ByNamespace(namespace).
-		List(podGVK, labels.Everything())
+	emitter, visitor, err := n.newVisitor()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := n.visitNamespace(visitor, namespace); err != nil {
+		return nil, err
+	}
+
+	return n.group(emitter)
+}
+
+// BuildNamespaces builds a list of nodes across namespaces. If no namespaces are supplied, all
+// namespaces are used. Cluster scoped objects are included and each namespace's nodes are
+// placed in a namespace group.
+func (n *NodeBuilder) BuildNamespaces(namespaces ...string) ([]GraphNode, error) {
+	emitter, visitor, err := n.newVisitor()
 	if err != nil {
-		return nil, fmt.Errorf("list pods: %w", err)
+		return nil, err
 	}
 
+	if err := n.visitNamespaces(visitor, namespaces); err != nil {
+		return nil, err
+	}
+
+	nodes, err := n.group(emitter)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewNamespaceGrouper().Group(nodes, emitter.Objects())
+}
+
+// BuildScope builds a list of nodes for a scope. A scope with a single namespace is built with
+// Build, otherwise it is built with BuildNamespaces.
+func (n *NodeBuilder) BuildScope(scope Scope) ([]GraphNode, error) {
+	switch {
+	case scope.AllNamespaces:
+		return n.BuildNamespaces()
+	case len(scope.Namespaces) == 0:
+		return n.Build(buildOptionConfig(n.options...).defaultNamespace)
+	case len(scope.Namespaces) == 1:
+		return n.Build(scope.Namespaces[0])
+	default:
+		return n.BuildNamespaces(scope.Namespaces...)
+	}
+}
+
+func (n *NodeBuilder) newVisitor() (*NodeEmitter, *Visitor, error) {
 	resourceVisitors := ResourceVisitorsFactory(n.lister)
 	emitter := NewNodeEmitter()
-	visitor, err := NewVisitor(emitter, n.lister, resourceVisitors)
+	visitor, err := NewVisitor(emitter, n.lister, resourceVisitors, n.options...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("create visitor: %w", err)
+	}
+
+	return emitter, visitor, nil
+}
+
+// visitNamespaces visits namespaces and cluster scoped objects. If no namespaces are supplied,
+// all namespaces are visited. Otherwise cluster scoped objects are not followed into other
+// namespaces.
+func (n *NodeBuilder) visitNamespaces(visitor *Visitor, namespaces []string) error {
+	visitor.namespaces = namespaces
+
+	if len(namespaces) == 0 {
+		objects, err := n.lister.List(namespaceGVK, labels.Everything())
+		if err != nil {
+			return fmt.Errorf("list namespaces: %w", err)
+		}
+
+		for _, object := range objects {
+			namespaces = append(namespaces, object.GetName())
+		}
+	}
+
+	for _, namespace := range namespaces {
+		if err := n.visitNamespace(visitor, namespace); err != nil {
+			return fmt.Errorf("namespace %s: %w", namespace, err)
+		}
+	}
+
+	for _, gvk := range clusterScopedGVKs {
+		objects, err := n.lister.List(gvk, labels.Everything())
+		if err != nil {
+			if isUnavailable(err) {
+				continue
+			}
+			return fmt.Errorf("list %s: %w", gvk.Kind, err)
+		}
+
+		if err := visitor.Visit(false, objects...); err != nil {
+			return fmt.Errorf("visit objects: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// visitNamespace visits a namespace starting from its pods and ingresses.
+func (n *NodeBuilder) visitNamespace(visitor *Visitor, namespace string) error {
+	objects, err := n.lister.
+		ByNamespace(namespace).
+		List(podGVK, labels.Everything())
 	if err != nil {
-		return nil, fmt.Errorf("create visitor: %w", err)
+		return fmt.Errorf("list pods: %w", err)
 	}
 
+	ingresses, err := n.lister.
+		ByNamespace(namespace).
+		List(ingressGVK, labels.Everything())
+	if err != nil && !isUnavailable(err) {
+		return fmt.Errorf("list ingresses: %w", err)
+	}
+
+	objects = append(objects, ingresses...)
+
 	if err := visitor.Visit(false, objects...); err != nil {
-		return nil, fmt.Errorf("visit objects: %w", err)
+		return fmt.Errorf("visit objects: %w", err)
 	}
 
-	return emitter.Nodes(), nil
+	return nil
+}
+
+func (n *NodeBuilder) group(emitter *NodeEmitter) ([]GraphNode, error) {
+	opts := buildOptionConfig(n.options...)
+
+	nodes := emitter.Nodes()
+
+	for _, factory := range opts.grouperFactories {
+		grouper, err := factory(n.lister)
+		if err != nil {
+			return nil, fmt.Errorf("create grouper: %w", err)
+		}
+
+		nodes, err = grouper.Group(nodes, emitter.Objects())
+		if err != nil {
+			return nil, fmt.Errorf("grouper %s: %w", grouper.Name(), err)
+		}
+	}
 
+	return nodes, nil
 }
@@ -0,0 +1,94 @@
+package rvnodegen
+
+import (
+	"testing"
+)
+
+func TestNodeBuilder_BuildNamespaces_scope(t *testing.T) {
+	resources := []fakeResource{
+		{gvk: serviceAccountGVK, resource: "serviceaccounts", namespaced: true},
+		{gvk: persistentVolumeClaimGVK, resource: "persistentvolumeclaims", namespaced: true},
+		{gvk: persistentVolumeGVK, resource: "persistentvolumes"},
+		{gvk: clusterRoleGVK, resource: "clusterroles"},
+		{gvk: clusterRoleBindingGVK, resource: "clusterrolebindings"},
+	}
+
+	inScopeAccount := newTestObject(serviceAccountGVK, "default", "app")
+	outOfScopeAccount := newTestObject(serviceAccountGVK, "other", "app")
+
+	clusterRole := newTestObject(clusterRoleGVK, "", "view")
+	clusterRoleBinding := newTestObject(clusterRoleBindingGVK, "", "view")
+	clusterRoleBinding.Object["roleRef"] = map[string]interface{}{"kind": "ClusterRole", "name": "view"}
+	clusterRoleBinding.Object["subjects"] = []interface{}{
+		map[string]interface{}{"kind": "ServiceAccount", "namespace": "default", "name": "app"},
+		map[string]interface{}{"kind": "ServiceAccount", "namespace": "other", "name": "app"},
+	}
+
+	inScopeClaim := newTestObject(persistentVolumeClaimGVK, "default", "data")
+	outOfScopeClaim := newTestObject(persistentVolumeClaimGVK, "other", "data")
+
+	inScopeVolume := newTestObject(persistentVolumeGVK, "", "pv-default")
+	inScopeVolume.Object["spec"] = map[string]interface{}{
+		"claimRef": map[string]interface{}{"namespace": "default", "name": "data"},
+	}
+
+	outOfScopeVolume := newTestObject(persistentVolumeGVK, "", "pv-other")
+	outOfScopeVolume.Object["spec"] = map[string]interface{}{
+		"claimRef": map[string]interface{}{"namespace": "other", "name": "data"},
+	}
+
+	lister := newFakeClusterLister(t, resources,
+		newTestObject(namespaceGVK, "", "default"), newTestObject(namespaceGVK, "", "other"),
+		inScopeAccount, outOfScopeAccount, clusterRole, clusterRoleBinding,
+		inScopeClaim, outOfScopeClaim, inScopeVolume, outOfScopeVolume)
+
+	tests := []struct {
+		name       string
+		namespaces []string
+		want       []string
+		wantAbsent []string
+	}{
+		{
+			name:       "namespace",
+			namespaces: []string{"default"},
+			want: []string{
+				string(inScopeAccount.GetUID()), string(inScopeClaim.GetUID()),
+				string(clusterRoleBinding.GetUID()), string(outOfScopeVolume.GetUID()),
+			},
+			wantAbsent: []string{
+				string(outOfScopeAccount.GetUID()), string(outOfScopeClaim.GetUID()),
+				namespaceGroupID("other"),
+			},
+		},
+		{
+			name: "all namespaces",
+			want: []string{
+				string(inScopeAccount.GetUID()), string(inScopeClaim.GetUID()),
+				string(outOfScopeAccount.GetUID()), string(outOfScopeClaim.GetUID()),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			nodes, err := NewNodeBuilder(lister).BuildNamespaces(test.namespaces...)
+			if err != nil {
+				t.Fatalf("build namespaces: %v", err)
+			}
+
+			byID := nodesByID(nodes)
+
+			for _, id := range test.want {
+				if _, ok := byID[id]; !ok {
+					t.Errorf("node %s was not emitted", id)
+				}
+			}
+
+			for _, id := range test.wantAbsent {
+				if _, ok := byID[id]; ok {
+					t.Errorf("node %s was emitted", id)
+				}
+			}
+		})
+	}
+}
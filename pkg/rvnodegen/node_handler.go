@@ -1,14 +1,21 @@
 package rvnodegen
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
 
 	"k8s.io/apimachinery/pkg/util/json"
 )
 
 type errorMessage struct {
-	Message string `json:"message"`
-	Status  int    `json:"status"`
+	Message string      `json:"message"`
+	Status  int         `json:"status"`
+	Details interface{} `json:"details,omitempty"`
 }
 
 type errorResponse struct {
@@ -25,41 +32,141 @@ func respondWithError(w http.ResponseWriter, err error, code int) {
 	enc.SetIndent("", "  ")
 
 	resp := errorResponse{
-		Error: errorMessage{
-			Message: err.Error(),
-			Status:  code,
-		},
+		Error: newErrorMessage(err, code),
 	}
 	_ = enc.Encode(resp)
 }
 
+// newErrorMessage creates an error message. Errors with structure, like filter errors, are
+// included as details.
+func newErrorMessage(err error, code int) errorMessage {
+	m := errorMessage{
+		Message: err.Error(),
+		Status:  code,
+	}
+
+	var filterErr *FilterError
+	if errors.As(err, &filterErr) {
+		m.Details = filterErr
+	}
+
+	return m
+}
+
+func respondWithJSON(w http.ResponseWriter, v interface{}, code int) {
+	w.WriteHeader(code)
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(v)
+}
+
+// maxNodeHandlerLayouts is the number of layouts a node handler keeps to seed later layouts.
+const maxNodeHandlerLayouts = 100
+
 // NodeHandler is a HTTP handler for generating nodes.
 type NodeHandler struct {
-	lister Lister
+	lister  Lister
+	options []Option
+
+	mu      sync.Mutex
+	layouts map[string]*LayeredLayout
 }
 
 var _ http.Handler = &NodeHandler{}
 
 // NewNodeHandler creates an instance of NodeHandler.
-func NewNodeHandler(lister Lister) *NodeHandler {
+func NewNodeHandler(lister Lister, options ...Option) *NodeHandler {
 	nh := &NodeHandler{
-		lister: lister,
+		lister:  lister,
+		options: options,
+		layouts: map[string]*LayeredLayout{},
 	}
 
 	return nh
 }
 
-func (nh *NodeHandler) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
-	nb := NewNodeBuilder(nh.lister)
-	nodes, err := nb.Build("default")
+func (nh *NodeHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	scope, err := scopeFromQuery(r.URL.Query())
 	if err != nil {
-		respondWithError(w, err, http.StatusInternalServerError)
+		respondWithError(w, err, http.StatusBadRequest)
+		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	resp := nodesResponse{Nodes: nodes}
+	nh.serveScope(w, r, scope)
+}
 
-	enc := json.NewEncoder(w)
-	enc.SetIndent("", "  ")
-	_ = enc.Encode(resp)
+// serveScope serves the nodes for a scope with the encoder selected by the request.
+func (nh *NodeHandler) serveScope(w http.ResponseWriter, r *http.Request, scope Scope) {
+	encoder, err := graphEncoderForRequest(r)
+	if err != nil {
+		respondWithError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	nh.serveScopeWithEncoder(w, r, scope, encoder)
+}
+
+// serveScopeWithEncoder serves the nodes for a scope with an encoder.
+func (nh *NodeHandler) serveScopeWithEncoder(w http.ResponseWriter, r *http.Request, scope Scope, encoder GraphEncoder) {
+	responseOptions, err := responseOptionsFromQuery(r.URL.Query())
+	if err != nil {
+		respondWithError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	nb := NewNodeBuilder(nh.lister, nh.options...)
+	nodes, err := nb.BuildScope(scope)
+	if err != nil {
+		respondWithError(w, err, clusterErrorStatus(err))
+		return
+	}
+
+	layout := nh.layout(r.URL.Query(), scope)
+	respondWithGraph(w, encoder, responseOptions.forEncoder(encoder).ApplyWithLayout(nodes, layout))
+}
+
+// layout returns the layout for a query and scope, so repeated requests for the same graph are
+// laid out from the previous positions.
+func (nh *NodeHandler) layout(values url.Values, scope Scope) *LayeredLayout {
+	nh.mu.Lock()
+	defer nh.mu.Unlock()
+
+	key := fmt.Sprintf("%s|%v", values.Encode(), scope)
+
+	layout, ok := nh.layouts[key]
+	if !ok {
+		if len(nh.layouts) >= maxNodeHandlerLayouts {
+			nh.layouts = map[string]*LayeredLayout{}
+		}
+
+		layout = NewLayeredLayout()
+		nh.layouts[key] = layout
+	}
+
+	return layout
+}
+
+// scopeFromQuery creates a scope from query parameters. Namespaces can be repeated or comma
+// separated.
+func scopeFromQuery(values url.Values) (Scope, error) {
+	scope := Scope{}
+
+	for _, value := range values["namespace"] {
+		for _, namespace := range strings.Split(value, ",") {
+			if namespace = strings.TrimSpace(namespace); namespace != "" {
+				scope.Namespaces = append(scope.Namespaces, namespace)
+			}
+		}
+	}
+
+	if value := values.Get("allNamespaces"); value != "" {
+		allNamespaces, err := strconv.ParseBool(value)
+		if err != nil {
+			return Scope{}, fmt.Errorf("invalid allNamespaces %q", value)
+		}
+		scope.AllNamespaces = allNamespaces
+	}
+
+	return scope, nil
 }
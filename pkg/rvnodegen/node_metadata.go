@@ -0,0 +1,259 @@
+package rvnodegen
+
+import (
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const lastAppliedConfigAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// NodeMetadata is metadata for the object a node represents.
+type NodeMetadata struct {
+	// APIVersion is the object's API version.
+	APIVersion string `json:"apiVersion,omitempty"`
+	// Kind is the object's kind.
+	Kind string `json:"kind,omitempty"`
+	// Name is the object's name.
+	Name string `json:"name,omitempty"`
+	// Namespace is the object's namespace.
+	Namespace string `json:"namespace,omitempty"`
+	// Labels are the object's labels.
+	Labels map[string]string `json:"labels,omitempty"`
+	// Annotations are the object's annotations.
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// CreationTimestamp is when the object was created.
+	CreationTimestamp *metav1.Time `json:"creationTimestamp,omitempty"`
+	// ResourceVersion is the object's resource version.
+	ResourceVersion string `json:"resourceVersion,omitempty"`
+	// Owners are the object's owners.
+	Owners []OwnerSummary `json:"owners,omitempty"`
+	// Status is a short summary of the object's status.
+	Status string `json:"status,omitempty"`
+}
+
+// OwnerSummary summarizes an owner reference.
+type OwnerSummary struct {
+	// Kind is the owner's kind.
+	Kind string `json:"kind"`
+	// Name is the owner's name.
+	Name string `json:"name"`
+	// Controller is true if the owner is the object's controller.
+	Controller bool `json:"controller,omitempty"`
+}
+
+// MetadataField is a node metadata field that can be requested.
+type MetadataField string
+
+const (
+	// MetadataFieldAPIVersion is the API version field.
+	MetadataFieldAPIVersion MetadataField = "apiVersion"
+	// MetadataFieldKind is the kind field.
+	MetadataFieldKind MetadataField = "kind"
+	// MetadataFieldName is the name field.
+	MetadataFieldName MetadataField = "name"
+	// MetadataFieldNamespace is the namespace field.
+	MetadataFieldNamespace MetadataField = "namespace"
+	// MetadataFieldLabels is the labels field.
+	MetadataFieldLabels MetadataField = "labels"
+	// MetadataFieldAnnotations is the annotations field.
+	MetadataFieldAnnotations MetadataField = "annotations"
+	// MetadataFieldCreationTimestamp is the creation timestamp field.
+	MetadataFieldCreationTimestamp MetadataField = "creationTimestamp"
+	// MetadataFieldResourceVersion is the resource version field.
+	MetadataFieldResourceVersion MetadataField = "resourceVersion"
+	// MetadataFieldOwners is the owners field.
+	MetadataFieldOwners MetadataField = "owners"
+	// MetadataFieldStatus is the status field.
+	MetadataFieldStatus MetadataField = "status"
+)
+
+var metadataFields = []MetadataField{MetadataFieldAPIVersion, MetadataFieldKind, MetadataFieldName,
+	MetadataFieldNamespace, MetadataFieldLabels, MetadataFieldAnnotations, MetadataFieldCreationTimestamp,
+	MetadataFieldResourceVersion, MetadataFieldOwners, MetadataFieldStatus}
+
+// parseMetadataFields parses a list of metadata fields. The field "all" selects every field.
+func parseMetadataFields(values []string) ([]MetadataField, error) {
+	var fields []MetadataField
+
+	for _, value := range values {
+		for _, name := range strings.Split(value, ",") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+
+			if name == "all" {
+				return metadataFields, nil
+			}
+
+			found := false
+			for _, field := range metadataFields {
+				if string(field) == name {
+					fields = append(fields, field)
+					found = true
+					break
+				}
+			}
+
+			if !found {
+				return nil, fmt.Errorf("unknown metadata field %q", name)
+			}
+		}
+	}
+
+	return fields, nil
+}
+
+func newNodeMetadata(object *unstructured.Unstructured) (*NodeMetadata, error) {
+	status, err := statusSummary(object)
+	if err != nil {
+		return nil, fmt.Errorf("summarize status: %w", err)
+	}
+
+	m := &NodeMetadata{
+		APIVersion:      object.GetAPIVersion(),
+		Kind:            object.GetKind(),
+		Name:            object.GetName(),
+		Namespace:       object.GetNamespace(),
+		Labels:          object.GetLabels(),
+		Annotations:     object.GetAnnotations(),
+		ResourceVersion: object.GetResourceVersion(),
+		Status:          status,
+	}
+
+	if ts := object.GetCreationTimestamp(); !ts.IsZero() {
+		m.CreationTimestamp = &ts
+	}
+
+	for _, ref := range object.GetOwnerReferences() {
+		m.Owners = append(m.Owners, OwnerSummary{
+			Kind:       ref.Kind,
+			Name:       ref.Name,
+			Controller: ref.Controller != nil && *ref.Controller,
+		})
+	}
+
+	return m, nil
+}
+
+// selectFields returns a copy of the metadata with only the requested fields. Annotations are
+// limited to annotation keys if any are supplied.
+func (m *NodeMetadata) selectFields(fields []MetadataField, annotationKeys []string) *NodeMetadata {
+	if m == nil || len(fields) == 0 {
+		return nil
+	}
+
+	out := &NodeMetadata{}
+
+	for _, field := range fields {
+		switch field {
+		case MetadataFieldAPIVersion:
+			out.APIVersion = m.APIVersion
+		case MetadataFieldKind:
+			out.Kind = m.Kind
+		case MetadataFieldName:
+			out.Name = m.Name
+		case MetadataFieldNamespace:
+			out.Namespace = m.Namespace
+		case MetadataFieldLabels:
+			out.Labels = m.Labels
+		case MetadataFieldAnnotations:
+			out.Annotations = selectAnnotations(m.Annotations, annotationKeys)
+		case MetadataFieldCreationTimestamp:
+			out.CreationTimestamp = m.CreationTimestamp
+		case MetadataFieldResourceVersion:
+			out.ResourceVersion = m.ResourceVersion
+		case MetadataFieldOwners:
+			out.Owners = m.Owners
+		case MetadataFieldStatus:
+			out.Status = m.Status
+		}
+	}
+
+	return out
+}
+
+// selectAnnotations selects annotations by key. If no keys are supplied, every annotation except
+// the last applied configuration is selected.
+func selectAnnotations(annotations map[string]string, keys []string) map[string]string {
+	selected := map[string]string{}
+
+	if len(keys) == 0 {
+		for k, v := range annotations {
+			if k != lastAppliedConfigAnnotation {
+				selected[k] = v
+			}
+		}
+		return selected
+	}
+
+	for _, k := range keys {
+		if v, ok := annotations[k]; ok {
+			selected[k] = v
+		}
+	}
+
+	return selected
+}
+
+// statusSummary creates a short summary of an object's status.
+func statusSummary(object *unstructured.Unstructured) (string, error) {
+	groupKind := object.GroupVersionKind().GroupKind()
+
+	switch {
+	case isGroupKindMatch(groupKind, []schema.GroupVersionKind{podGVK}):
+		status, err := readPodStatus(object)
+		if err != nil {
+			return "", err
+		}
+		if status.phase == "Running" && !status.ready {
+			return "Running (not ready)", nil
+		}
+		return status.phase, nil
+	case isGroupKindMatch(groupKind, []schema.GroupVersionKind{deploymentGVK, replicaSetGVK, statefulSetGVK,
+		replicationControllerGVK}):
+		return replicaStatus(object, []string{"status", "readyReplicas"}, []string{"spec", "replicas"}, "ready")
+	case isGroupKindMatch(groupKind, []schema.GroupVersionKind{daemonSetGVK}):
+		return replicaStatus(object, []string{"status", "numberReady"}, []string{"status", "desiredNumberScheduled"}, "ready")
+	case isGroupKindMatch(groupKind, []schema.GroupVersionKind{jobGVK}):
+		return replicaStatus(object, []string{"status", "succeeded"}, []string{"spec", "completions"}, "succeeded")
+	case isGroupKindMatch(groupKind, []schema.GroupVersionKind{serviceGVK}):
+		serviceType, _, err := unstructured.NestedString(object.Object, "spec", "type")
+		return serviceType, err
+	case isGroupKindMatch(groupKind, []schema.GroupVersionKind{persistentVolumeGVK, persistentVolumeClaimGVK}):
+		phase, _, err := unstructured.NestedString(object.Object, "status", "phase")
+		return phase, err
+	}
+
+	ready, found, err := findCondition(object, "Ready")
+	if err != nil || !found {
+		return "", err
+	}
+
+	if ready.Status == "True" {
+		return "Ready", nil
+	}
+
+	return "NotReady", nil
+}
+
+func replicaStatus(object *unstructured.Unstructured, readyPath, desiredPath []string, state string) (string, error) {
+	ready, _, err := unstructured.NestedInt64(object.Object, readyPath...)
+	if err != nil {
+		return "", err
+	}
+
+	desired, found, err := unstructured.NestedInt64(object.Object, desiredPath...)
+	if err != nil {
+		return "", err
+	}
+	if !found {
+		desired = 1
+	}
+
+	return fmt.Sprintf("%d/%d %s", ready, desired, state), nil
+}
@@ -1,6 +1,12 @@
 package rvnodegen
 
-import "time"
+import (
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/bryanl/rv-node-gen/internal/log"
+)
 
 type optionConfig struct {
 	discoveryCacheDir string
@@ -8,6 +14,13 @@ type optionConfig struct {
 	discoveryTTL      time.Duration
 
 	healthStatuserFactory HealthStatuserFactory
+	grouperFactories      []GrouperFactory
+	expandRevisionHistory bool
+	argoCDInstanceLabel   string
+	defaultNamespace      string
+	accessNamespaces      []string
+	cacheSyncTimeout      time.Duration
+	logger                logr.Logger
 }
 
 func buildOptionConfig(options ...Option) optionConfig {
@@ -15,6 +28,9 @@ func buildOptionConfig(options ...Option) optionConfig {
 		discoveryCacheDir: "",
 		httpCacheDir:      "",
 		discoveryTTL:      180 * time.Second,
+		defaultNamespace:  DefaultNamespace,
+		cacheSyncTimeout:  2 * time.Minute,
+		logger:            log.Nop(),
 		healthStatuserFactory: func(lister Lister) (HealthStatuser, error) {
 			hs := NewClusterHealthStatus(lister)
 			return hs, nil
@@ -58,3 +74,75 @@ func HealthStatusFactory(f HealthStatuserFactory) Option {
 		o.healthStatuserFactory = f
 	}
 }
+
+// Groupers adds grouper factories. Groupers run in the order they are added.
+func Groupers(factories ...GrouperFactory) Option {
+	return func(o *optionConfig) {
+		o.grouperFactories = append(o.grouperFactories, factories...)
+	}
+}
+
+// ApplicationGrouping groups objects into applications using labels.
+func ApplicationGrouping(keys ApplicationLabelKeys) Option {
+	return Groupers(func(lister Lister) (Grouper, error) {
+		return NewApplicationGrouper(keys), nil
+	})
+}
+
+// HelmReleaseGrouping groups objects by the Helm release that installed them. Release secrets
+// that can't be decoded are logged with the logger set with Logger.
+func HelmReleaseGrouping() Option {
+	return func(o *optionConfig) {
+		// the factory runs after every option is applied, so it uses the final logger.
+		o.grouperFactories = append(o.grouperFactories, func(lister Lister) (Grouper, error) {
+			return NewHelmReleaseGrouper(lister, o.logger), nil
+		})
+	}
+}
+
+// ExpandRevisionHistory shows old deployment revisions that are scaled to zero as nodes.
+func ExpandRevisionHistory() Option {
+	return func(o *optionConfig) {
+		o.expandRevisionHistory = true
+	}
+}
+
+// ArgoCDInstanceLabel finds the Argo CD applications managing objects with an instance label, for
+// Argo CD installations that track resources with labels. Objects are only matched with their
+// tracking id annotation by default, since other tools set the common instance label too.
+func ArgoCDInstanceLabel(key string) Option {
+	return func(o *optionConfig) {
+		o.argoCDInstanceLabel = key
+	}
+}
+
+// DefaultScopeNamespace sets the namespace built for scopes without namespaces.
+func DefaultScopeNamespace(namespace string) Option {
+	return func(o *optionConfig) {
+		o.defaultNamespace = namespace
+	}
+}
+
+// CacheSyncTimeout sets how long informer caches have to sync. Resources whose caches don't sync
+// in time are skipped.
+func CacheSyncTimeout(timeout time.Duration) Option {
+	return func(o *optionConfig) {
+		o.cacheSyncTimeout = timeout
+	}
+}
+
+// AccessNamespaces sets the namespaces checked for namespaced access when the user can't list
+// namespaces.
+func AccessNamespaces(namespaces ...string) Option {
+	return func(o *optionConfig) {
+		o.accessNamespaces = namespaces
+	}
+}
+
+// Logger sets the logger for problems that don't fail a build, like Helm release secrets that
+// can't be decoded. Nothing is logged by default.
+func Logger(logger logr.Logger) Option {
+	return func(o *optionConfig) {
+		o.logger = logger
+	}
+}
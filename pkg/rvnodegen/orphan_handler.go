@@ -0,0 +1,64 @@
+package rvnodegen
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// OrphanHandler is a HTTP handler for reporting unused objects.
+type OrphanHandler struct {
+	lister  Lister
+	options []Option
+}
+
+var _ http.Handler = &OrphanHandler{}
+
+// NewOrphanHandler creates an instance of OrphanHandler.
+func NewOrphanHandler(lister Lister, options ...Option) *OrphanHandler {
+	h := &OrphanHandler{
+		lister:  lister,
+		options: options,
+	}
+	return h
+}
+
+// ServeHTTP serves the handler. The namespaces checked are set with the namespace and
+// allNamespaces query parameters, and the kind query parameter limits the kinds checked.
+func (h *OrphanHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	scope, err := scopeFromQuery(r.URL.Query())
+	if err != nil {
+		respondWithError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	var kinds []string
+	for _, value := range r.URL.Query()["kind"] {
+		for _, kind := range strings.Split(value, ",") {
+			if kind = strings.TrimSpace(kind); kind != "" {
+				kinds = append(kinds, kind)
+			}
+		}
+	}
+
+	nb := NewNodeBuilder(h.lister, h.options...)
+	report, err := nb.Orphans(scope, kinds...)
+	if err != nil {
+		respondWithError(w, err, orphanErrorStatus(err))
+		return
+	}
+
+	respondWithJSON(w, report, http.StatusOK)
+}
+
+// orphanErrorStatus returns the HTTP status for an error reporting orphans.
+func orphanErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrInvalidNamespace), errors.Is(err, ErrInvalidOrphanKind):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrNamespaceNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
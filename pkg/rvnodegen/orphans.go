@@ -0,0 +1,327 @@
+package rvnodegen
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	// OrphanReasonUnreferenced is an object no live workload or ingress references.
+	OrphanReasonUnreferenced = "unreferenced"
+	// OrphanReasonNoEndpoints is a service without ready endpoints.
+	OrphanReasonNoEndpoints = "no ready endpoints"
+
+	helmReleaseSecretType         = "helm.sh/release.v1"
+	serviceAccountTokenSecretType = "kubernetes.io/service-account-token"
+
+	// rootCAConfigMapName is the config map the root ca publisher creates in every namespace.
+	rootCAConfigMapName = "kube-root-ca.crt"
+)
+
+var (
+	// ErrInvalidNamespace is returned when a namespace name is not a valid namespace name.
+	ErrInvalidNamespace = errors.New("invalid namespace")
+	// ErrNamespaceNotFound is returned when a namespace does not exist.
+	ErrNamespaceNotFound = errors.New("namespace not found")
+	// ErrInvalidOrphanKind is returned when a kind is not checked for orphans.
+	ErrInvalidOrphanKind = errors.New("invalid orphan kind")
+)
+
+// orphanGVKs are the kinds checked for orphans.
+var orphanGVKs = []schema.GroupVersionKind{configMapGVK, secretGVK, serviceGVK, serviceAccountGVK}
+
+// OrphanReport lists objects that are not used by live workloads.
+type OrphanReport struct {
+	// Namespaces are the namespaces that were checked.
+	Namespaces []string `json:"namespaces"`
+	// GeneratedAt is when the report was generated.
+	GeneratedAt metav1.Time `json:"generatedAt"`
+	// Orphans are the unused objects.
+	Orphans []Orphan `json:"orphans"`
+}
+
+// Orphan is an unused object.
+type Orphan struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace"`
+	Name       string `json:"name"`
+	UID        string `json:"uid"`
+	// Reason explains why the object is an orphan.
+	Reason string `json:"reason"`
+	// CreationTimestamp is when the object was created.
+	CreationTimestamp *metav1.Time `json:"creationTimestamp,omitempty"`
+	// LastModified is the latest managed fields update, or the creation time if there are none.
+	LastModified *metav1.Time `json:"lastModified,omitempty"`
+	// Age is the time since the object was created.
+	Age string `json:"age,omitempty"`
+}
+
+// Orphans reports the config maps, secrets, service accounts and services in a scope that live
+// workloads don't use. Config maps, secrets and service accounts are orphans if visiting the
+// namespace's pods and ingresses does not reach them. Secrets are reached through volumes, the
+// environment and image pull secrets of pods and their service accounts. Services are orphans if they have no ready
+// endpoints, or for services without a selector, no endpoints object. Kinds limit the report to
+// some of the checked kinds, e.g. ConfigMap.
+func (n *NodeBuilder) Orphans(scope Scope, kinds ...string) (OrphanReport, error) {
+	gvks, err := orphanKinds(kinds)
+	if err != nil {
+		return OrphanReport{}, err
+	}
+
+	namespaces, err := n.scopeNamespaces(scope)
+	if err != nil {
+		return OrphanReport{}, err
+	}
+
+	if !scope.AllNamespaces {
+		if err := n.checkNamespaces(namespaces); err != nil {
+			return OrphanReport{}, err
+		}
+	}
+
+	now := time.Now()
+
+	report := OrphanReport{
+		Namespaces:  namespaces,
+		GeneratedAt: metav1.NewTime(now),
+		Orphans:     []Orphan{},
+	}
+
+	for _, namespace := range namespaces {
+		orphans, err := n.namespaceOrphans(namespace, gvks, now)
+		if err != nil {
+			return OrphanReport{}, fmt.Errorf("namespace %s: %w", namespace, err)
+		}
+
+		report.Orphans = append(report.Orphans, orphans...)
+	}
+
+	return report, nil
+}
+
+// scopeNamespaces returns the namespaces in a scope.
+func (n *NodeBuilder) scopeNamespaces(scope Scope) ([]string, error) {
+	switch {
+	case scope.AllNamespaces:
+		objects, err := n.lister.List(namespaceGVK, labels.Everything())
+		if err != nil {
+			return nil, fmt.Errorf("list namespaces: %w", err)
+		}
+
+		var namespaces []string
+		for _, object := range objects {
+			namespaces = append(namespaces, object.GetName())
+		}
+		sort.Strings(namespaces)
+
+		return namespaces, nil
+	case len(scope.Namespaces) == 0:
+		return []string{buildOptionConfig(n.options...).defaultNamespace}, nil
+	default:
+		return scope.Namespaces, nil
+	}
+}
+
+// orphanKinds returns the kinds to check for orphans. No kinds checks every kind.
+func orphanKinds(kinds []string) ([]schema.GroupVersionKind, error) {
+	if len(kinds) == 0 {
+		return orphanGVKs, nil
+	}
+
+	var gvks []schema.GroupVersionKind
+	for _, kind := range kinds {
+		gvk, ok := orphanGVK(kind)
+		if !ok {
+			return nil, fmt.Errorf("%q: %w", kind, ErrInvalidOrphanKind)
+		}
+		gvks = append(gvks, gvk)
+	}
+
+	return gvks, nil
+}
+
+// orphanGVK returns the checked group/version/kind for a kind. Kinds are case insensitive.
+func orphanGVK(kind string) (schema.GroupVersionKind, bool) {
+	for _, gvk := range orphanGVKs {
+		if strings.EqualFold(kind, gvk.Kind) {
+			return gvk, true
+		}
+	}
+
+	return schema.GroupVersionKind{}, false
+}
+
+// checkNamespaces returns an error if a namespace is invalid or does not exist. Namespaces are
+// assumed to exist if they can't be read.
+func (n *NodeBuilder) checkNamespaces(namespaces []string) error {
+	for _, namespace := range namespaces {
+		if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+			return fmt.Errorf("%q: %s: %w", namespace, strings.Join(errs, ", "), ErrInvalidNamespace)
+		}
+
+		if _, err := n.lister.Get(namespaceGVK, namespace); err != nil {
+			if isMissing(err) {
+				return fmt.Errorf("%s: %w", namespace, ErrNamespaceNotFound)
+			}
+			if isUnknownResource(err) {
+				continue
+			}
+			return fmt.Errorf("get namespace %s: %w", namespace, err)
+		}
+	}
+
+	return nil
+}
+
+func (n *NodeBuilder) namespaceOrphans(namespace string, gvks []schema.GroupVersionKind, now time.Time) ([]Orphan, error) {
+	emitter, visitor, err := n.newVisitor()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := n.visitNamespace(visitor, namespace); err != nil {
+		return nil, err
+	}
+
+	reached := emitter.Objects()
+
+	var orphans []Orphan
+
+	for _, gvk := range gvks {
+		objects, err := n.lister.ByNamespace(namespace).List(gvk, labels.Everything())
+		if err != nil {
+			return nil, fmt.Errorf("list %s: %w", gvk.Kind, err)
+		}
+
+		sort.Slice(objects, func(i, j int) bool {
+			return objects[i].GetName() < objects[j].GetName()
+		})
+
+		for _, object := range objects {
+			reason, err := n.orphanReason(object, reached)
+			if err != nil {
+				return nil, err
+			}
+
+			if reason != "" {
+				orphans = append(orphans, newOrphan(object, reason, now))
+			}
+		}
+	}
+
+	return orphans, nil
+}
+
+// orphanReason returns why an object is an orphan, or an empty string if it is in use.
+func (n *NodeBuilder) orphanReason(object *unstructured.Unstructured, reached map[string]*unstructured.Unstructured) (string, error) {
+	switch object.GetKind() {
+	case serviceGVK.Kind:
+		return n.serviceOrphanReason(object)
+	case secretGVK.Kind:
+		// Helm stores release state in secrets, and the token controller manages service account
+		// tokens. Neither is meant to be referenced.
+		secretType, _, _ := unstructured.NestedString(object.Object, "type")
+		if secretType == helmReleaseSecretType || secretType == serviceAccountTokenSecretType {
+			return "", nil
+		}
+	case configMapGVK.Kind:
+		// the root ca config map is published to every namespace for pods that talk to the api
+		// server, whether or not they mount it.
+		if object.GetName() == rootCAConfigMapName {
+			return "", nil
+		}
+	case serviceAccountGVK.Kind:
+		// every namespace has a default service account.
+		if object.GetName() == "default" {
+			return "", nil
+		}
+	}
+
+	if _, ok := reached[string(object.GetUID())]; ok {
+		return "", nil
+	}
+
+	return OrphanReasonUnreferenced, nil
+}
+
+func (n *NodeBuilder) serviceOrphanReason(service *unstructured.Unstructured) (string, error) {
+	// external name services are resolved through DNS and don't have endpoints.
+	if serviceType, _, _ := unstructured.NestedString(service.Object, "spec", "type"); serviceType == "ExternalName" {
+		return "", nil
+	}
+
+	endpoints, err := n.lister.ByNamespace(service.GetNamespace()).Get(endpointsGVK, service.GetName())
+	if err != nil {
+		if isMissing(err) {
+			return OrphanReasonNoEndpoints, nil
+		}
+		// endpoints that can't be read don't show the service is unused.
+		if isUnknownResource(err) {
+			return "", nil
+		}
+		return "", fmt.Errorf("get endpoints %s: %w", service.GetName(), err)
+	}
+
+	// services without a selector have manually managed endpoints, and their readiness is not
+	// tracked, so the endpoints object existing is enough.
+	if _, ok, err := serviceSelector(service); err != nil {
+		return "", err
+	} else if !ok {
+		return "", nil
+	}
+
+	subsets, _, err := unstructured.NestedSlice(endpoints.Object, "subsets")
+	if err != nil {
+		return "", err
+	}
+
+	for i := range subsets {
+		subset, ok := subsets[i].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		if addresses, _, _ := unstructured.NestedSlice(subset, "addresses"); len(addresses) > 0 {
+			return "", nil
+		}
+	}
+
+	return OrphanReasonNoEndpoints, nil
+}
+
+func newOrphan(object *unstructured.Unstructured, reason string, now time.Time) Orphan {
+	orphan := Orphan{
+		APIVersion: object.GetAPIVersion(),
+		Kind:       object.GetKind(),
+		Namespace:  object.GetNamespace(),
+		Name:       object.GetName(),
+		UID:        string(object.GetUID()),
+		Reason:     reason,
+	}
+
+	created := object.GetCreationTimestamp()
+	if !created.IsZero() {
+		orphan.CreationTimestamp = &created
+		orphan.LastModified = &created
+		orphan.Age = now.Sub(created.Time).Round(time.Second).String()
+	}
+
+	for _, entry := range object.GetManagedFields() {
+		if entry.Time != nil && (orphan.LastModified == nil || entry.Time.After(orphan.LastModified.Time)) {
+			modified := *entry.Time
+			orphan.LastModified = &modified
+		}
+	}
+
+	return orphan
+}
@@ -0,0 +1,99 @@
+package rvnodegen
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func newOrphanTestLister(t *testing.T) Lister {
+	t.Helper()
+
+	resources := []fakeResource{
+		{gvk: serviceAccountGVK, resource: "serviceaccounts", namespaced: true},
+		{gvk: endpointsGVK, resource: "endpoints", namespaced: true},
+	}
+
+	rootCA := newTestObject(configMapGVK, "default", rootCAConfigMapName)
+	unused := newTestObject(configMapGVK, "default", "unused")
+
+	selected := newTestObject(serviceGVK, "default", "selected")
+	if err := unstructured.SetNestedStringMap(selected.Object, map[string]string{"app": "web"}, "spec", "selector"); err != nil {
+		t.Fatalf("set selector: %v", err)
+	}
+	selectedEndpoints := newTestObject(endpointsGVK, "default", "selected")
+	notReady := []interface{}{map[string]interface{}{"ip": "10.0.0.1"}}
+	if err := unstructured.SetNestedSlice(selectedEndpoints.Object, []interface{}{
+		map[string]interface{}{"notReadyAddresses": notReady},
+	}, "subsets"); err != nil {
+		t.Fatalf("set subsets: %v", err)
+	}
+
+	manual := newTestObject(serviceGVK, "default", "manual")
+	manualEndpoints := newTestObject(endpointsGVK, "default", "manual")
+	missing := newTestObject(serviceGVK, "default", "missing")
+
+	return newFakeClusterLister(t, resources,
+		newTestObject(namespaceGVK, "", "default"),
+		rootCA, unused, selected, selectedEndpoints, manual, manualEndpoints, missing)
+}
+
+func TestNodeBuilder_Orphans(t *testing.T) {
+	lister := newOrphanTestLister(t)
+
+	report, err := NewNodeBuilder(lister).Orphans(Scope{Namespaces: []string{"default"}})
+	if err != nil {
+		t.Fatalf("Orphans() error = %v", err)
+	}
+
+	got := map[string]string{}
+	for _, orphan := range report.Orphans {
+		got[orphan.Kind+"/"+orphan.Name] = orphan.Reason
+	}
+
+	want := map[string]string{
+		"ConfigMap/unused": OrphanReasonUnreferenced,
+		"Service/selected": OrphanReasonNoEndpoints,
+		"Service/missing":  OrphanReasonNoEndpoints,
+	}
+
+	if len(got) != len(want) {
+		t.Errorf("orphans = %v, want %v", got, want)
+	}
+	for name, reason := range want {
+		if got[name] != reason {
+			t.Errorf("orphan %s reason = %q, want %q", name, got[name], reason)
+		}
+	}
+}
+
+func TestOrphanHandler(t *testing.T) {
+	lister := newOrphanTestLister(t)
+
+	tests := []struct {
+		name  string
+		query string
+		want  int
+	}{
+		{name: "namespace", query: "namespace=default", want: http.StatusOK},
+		{name: "kind", query: "namespace=default&kind=configmap,Service", want: http.StatusOK},
+		{name: "invalid kind", query: "namespace=default&kind=Pod", want: http.StatusBadRequest},
+		{name: "invalid namespace", query: "namespace=Not_Valid", want: http.StatusBadRequest},
+		{name: "missing namespace", query: "namespace=missing", want: http.StatusNotFound},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/orphans?"+test.query, nil)
+			w := httptest.NewRecorder()
+
+			NewOrphanHandler(lister).ServeHTTP(w, r)
+
+			if w.Code != test.want {
+				t.Errorf("status = %d, want %d: %s", w.Code, test.want, w.Body.String())
+			}
+		})
+	}
+}
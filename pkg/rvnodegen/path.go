@@ -0,0 +1,158 @@
+package rvnodegen
+
+import (
+	"fmt"
+)
+
+// DefaultPathLimit is the maximum number of shortest paths returned by default.
+const DefaultPathLimit = 10
+
+// PathReport is the set of shortest paths between two nodes.
+type PathReport struct {
+	// Source is the id of the node paths start from.
+	Source string `json:"source"`
+	// Target is the id of the node paths end at.
+	Target string `json:"target"`
+	// Paths are the shortest paths. It is empty if the nodes aren't connected.
+	Paths []Path `json:"paths"`
+}
+
+// Path is a sequence of hops from one node to another.
+type Path struct {
+	Hops []PathHop `json:"hops"`
+}
+
+// PathHop is a single edge on a path.
+type PathHop struct {
+	// From is the id of the node the hop leaves.
+	From string `json:"from"`
+	// To is the id of the node the hop arrives at.
+	To string `json:"to"`
+	// Edge is the edge explaining the hop.
+	Edge Edge `json:"edge"`
+	// Reverse is true if the hop follows the edge against its direction.
+	Reverse bool `json:"reverse,omitempty"`
+}
+
+// ExplainPaths builds the graph around two objects and finds the shortest paths between them.
+func (n *NodeBuilder) ExplainPaths(source, target ObjectReference, limit int) (PathReport, error) {
+	nodes, ids, err := n.buildAround(source, target)
+	if err != nil {
+		return PathReport{}, err
+	}
+
+	return FindPaths(nodes, ids[0], ids[1], limit)
+}
+
+// FindPaths finds up to limit shortest paths between two nodes. Edges are followed in both
+// directions, and nodes are connected to the workloads that contain them.
+func FindPaths(nodes []GraphNode, sourceID, targetID string, limit int) (PathReport, error) {
+	byID := nodesByID(nodes)
+
+	for _, id := range []string{sourceID, targetID} {
+		if _, ok := byID[id]; !ok {
+			return PathReport{}, fmt.Errorf("node %s: %w", id, ErrObjectNotFound)
+		}
+	}
+
+	report := PathReport{
+		Source: sourceID,
+		Target: targetID,
+		Paths:  []Path{},
+	}
+
+	adjacency := pathAdjacency(nodes, byID)
+
+	// breadth first search recording every shortest way of reaching each node.
+	distance := map[string]int{sourceID: 0}
+	previous := map[string][]PathHop{}
+	frontier := []string{sourceID}
+
+	for len(frontier) > 0 {
+		if _, ok := distance[targetID]; ok {
+			break
+		}
+
+		var next []string
+		for _, id := range frontier {
+			for _, hop := range adjacency[id] {
+				d, seen := distance[hop.To]
+				if !seen {
+					distance[hop.To] = distance[id] + 1
+					next = append(next, hop.To)
+				} else if d != distance[id]+1 {
+					continue
+				}
+				previous[hop.To] = append(previous[hop.To], hop)
+			}
+		}
+		frontier = next
+	}
+
+	if _, ok := distance[targetID]; !ok || sourceID == targetID {
+		return report, nil
+	}
+
+	var walk func(id string, hops []PathHop)
+	walk = func(id string, hops []PathHop) {
+		if len(report.Paths) >= limit {
+			return
+		}
+
+		if id == sourceID {
+			path := Path{Hops: make([]PathHop, len(hops))}
+			for i := range hops {
+				path.Hops[i] = hops[len(hops)-1-i]
+			}
+			report.Paths = append(report.Paths, path)
+			return
+		}
+
+		for _, hop := range previous[id] {
+			walk(hop.From, append(hops, hop))
+		}
+	}
+	walk(targetID, nil)
+
+	return report, nil
+}
+
+// pathAdjacency returns the hops leaving each node.
+func pathAdjacency(nodes []GraphNode, byID map[string]GraphNode) map[string][]PathHop {
+	adjacency := map[string][]PathHop{}
+
+	add := func(edge Edge) {
+		adjacency[edge.Source] = append(adjacency[edge.Source], PathHop{From: edge.Source, To: edge.Target, Edge: edge})
+		adjacency[edge.Target] = append(adjacency[edge.Target], PathHop{From: edge.Target, To: edge.Source, Edge: edge, Reverse: true})
+	}
+
+	for _, node := range nodes {
+		for _, edge := range nodeEdges(node) {
+			add(edge)
+		}
+
+		if node.Parent == nil {
+			continue
+		}
+
+		// synthetic groups don't explain a relationship, so only parents for objects are used.
+		parent, ok := byID[*node.Parent]
+		if !ok || parent.Metadata == nil || node.Metadata == nil {
+			continue
+		}
+
+		add(Edge{
+			Source:   node.ID,
+			Target:   parent.ID,
+			Relation: EdgeRelationOwner,
+			Provenance: &EdgeProvenance{
+				Visitor: "Visitor",
+				Field:   "parent",
+				Description: fmt.Sprintf("%s %s is part of %s %s",
+					node.Metadata.Kind, node.Label, parent.Metadata.Kind, parent.Label),
+			},
+		})
+	}
+
+	return adjacency
+}
@@ -0,0 +1,57 @@
+package rvnodegen
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+// PathHandler is a HTTP handler for explaining how two objects are connected.
+type PathHandler struct {
+	lister  Lister
+	options []Option
+}
+
+var _ http.Handler = &PathHandler{}
+
+// NewPathHandler creates an instance of PathHandler.
+func NewPathHandler(lister Lister, options ...Option) *PathHandler {
+	h := &PathHandler{
+		lister:  lister,
+		options: options,
+	}
+	return h
+}
+
+// ServeHTTP serves the handler. The objects are identified with query parameters prefixed with
+// "from." and "to.", e.g. from.kind and to.name. limit caps the number of paths.
+func (h *PathHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	source := objectReferenceFromQuery(r.URL.Query(), "from.")
+	target := objectReferenceFromQuery(r.URL.Query(), "to.")
+
+	for _, ref := range []ObjectReference{source, target} {
+		if err := ref.Validate(); err != nil {
+			respondWithError(w, err, http.StatusBadRequest)
+			return
+		}
+	}
+
+	limit := DefaultPathLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		var err error
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 1 {
+			respondWithError(w, fmt.Errorf("invalid limit %q", value), http.StatusBadRequest)
+			return
+		}
+	}
+
+	nb := NewNodeBuilder(h.lister, h.options...)
+	report, err := nb.ExplainPaths(source, target, limit)
+	if err != nil {
+		respondWithError(w, err, objectErrorStatus(err))
+		return
+	}
+
+	respondWithJSON(w, report, http.StatusOK)
+}
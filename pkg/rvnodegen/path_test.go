@@ -0,0 +1,90 @@
+package rvnodegen
+
+import (
+	"testing"
+)
+
+func TestNodeBuilder_ExplainPaths(t *testing.T) {
+	configMap := newTestObject(configMapGVK, "default", "config")
+	unrelated := newTestObject(configMapGVK, "default", "unrelated")
+	deployment, replicaSet, pod := newTestDeployment("default", "api", "config")
+
+	lister := newFakeClusterLister(t, fakeWorkloadResources, configMap, unrelated, deployment, replicaSet, pod)
+
+	tests := []struct {
+		name       string
+		source     ObjectReference
+		target     ObjectReference
+		wantSource string
+		wantTarget string
+		wantHops   []EdgeRelation
+	}{
+		{
+			name:       "summarized pod to config map",
+			source:     objectReference(pod),
+			target:     objectReference(configMap),
+			wantSource: string(replicaSet.GetUID()),
+			wantTarget: string(configMap.GetUID()),
+			wantHops:   []EdgeRelation{EdgeRelationVolume},
+		},
+		{
+			name:       "deployment to summarized pod",
+			source:     objectReference(deployment),
+			target:     objectReference(pod),
+			wantSource: string(deployment.GetUID()),
+			wantTarget: string(replicaSet.GetUID()),
+			wantHops:   []EdgeRelation{EdgeRelationOwner},
+		},
+		{
+			name:       "not connected",
+			source:     objectReference(pod),
+			target:     objectReference(unrelated),
+			wantSource: string(replicaSet.GetUID()),
+			wantTarget: string(unrelated.GetUID()),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			report, err := NewNodeBuilder(lister).ExplainPaths(test.source, test.target, DefaultPathLimit)
+			if err != nil {
+				t.Fatalf("explain paths: %v", err)
+			}
+
+			if report.Source != test.wantSource {
+				t.Errorf("source = %q, want %q", report.Source, test.wantSource)
+			}
+
+			if report.Target != test.wantTarget {
+				t.Errorf("target = %q, want %q", report.Target, test.wantTarget)
+			}
+
+			if test.wantHops == nil {
+				if len(report.Paths) != 0 {
+					t.Errorf("paths = %v, want none", report.Paths)
+				}
+				return
+			}
+
+			if len(report.Paths) != 1 {
+				t.Fatalf("got %d paths, want 1", len(report.Paths))
+			}
+
+			var got []EdgeRelation
+			for _, hop := range report.Paths[0].Hops {
+				got = append(got, hop.Edge.Relation)
+			}
+
+			if len(got) != len(test.wantHops) {
+				t.Fatalf("hops = %v, want %v", got, test.wantHops)
+			}
+
+			for i := range got {
+				if got[i] != test.wantHops[i] {
+					t.Errorf("hops = %v, want %v", got, test.wantHops)
+					break
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package rvnodegen
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// PersistentVolumeVisitor visits a persistent volume resource.
+type PersistentVolumeVisitor struct {
+	lister Lister
+}
+
+var _ ResourceVisitor = &PersistentVolumeVisitor{}
+
+// NewPersistentVolumeVisitor creates an instance of PersistentVolumeVisitor.
+func NewPersistentVolumeVisitor(lister Lister) *PersistentVolumeVisitor {
+	p := &PersistentVolumeVisitor{
+		lister: lister,
+	}
+	return p
+}
+
+// Name is the name of the resource visitor.
+func (p *PersistentVolumeVisitor) Name() string {
+	return "PersistentVolume"
+}
+
+// Matches returns a group/version/kind that this resource visitor matches.
+func (p *PersistentVolumeVisitor) Matches(gvk schema.GroupVersionKind) bool {
+	return persistentVolumeGVK.String() == gvk.String()
+}
+
+// Visit visits a persistent volume resource. It targets the volume's storage class and the
+// claim bound to it if the claim is in the visitor's namespaces.
+func (p *PersistentVolumeVisitor) Visit(object *unstructured.Unstructured, node GraphNode, visitor *Visitor) (GraphNode, error) {
+	references, err := persistentVolumeReferences(object)
+	if err != nil {
+		return GraphNode{}, err
+	}
+
+	return visitEdgeReferences(visitor, p.Name(), object, references, node)
+}
+
+// persistentVolumeReferences returns the references a persistent volume makes to its storage
+// class and the claim bound to it.
+func persistentVolumeReferences(object *unstructured.Unstructured) ([]edgeReference, error) {
+	var references []edgeReference
+
+	storageClassName, _, err := unstructured.NestedString(object.Object, "spec", "storageClassName")
+	if err != nil {
+		return nil, err
+	}
+
+	if storageClassName != "" {
+		references = append(references, edgeReference{gvk: storageClassGVK, name: storageClassName,
+			relation: EdgeRelationVolume, attributes: map[string]string{"field": "storageClassName"},
+			field: "spec.storageClassName", verb: "uses"})
+	}
+
+	claimNamespace, _, err := unstructured.NestedString(object.Object, "spec", "claimRef", "namespace")
+	if err != nil {
+		return nil, err
+	}
+
+	claimName, _, err := unstructured.NestedString(object.Object, "spec", "claimRef", "name")
+	if err != nil {
+		return nil, err
+	}
+
+	if claimName != "" {
+		references = append(references, edgeReference{gvk: persistentVolumeClaimGVK, namespace: claimNamespace,
+			name: claimName, relation: EdgeRelationVolume, attributes: map[string]string{"field": "claimRef"},
+			field: "spec.claimRef", verb: "is bound to"})
+	}
+
+	return references, nil
+}
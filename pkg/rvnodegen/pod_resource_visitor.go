@@ -40,6 +40,19 @@ func (p *PodResourceVisitor) Matches(gvk schema.GroupVersionKind) bool {
 
 // Visit visits a pod resource.
 func (p *PodResourceVisitor) Visit(object *unstructured.Unstructured, node GraphNode, visitor *Visitor) (GraphNode, error) {
+	summarized, err := isSummarizedPod(p.lister, object)
+	if err != nil {
+		return GraphNode{}, err
+	}
+
+	if !summarized {
+		// bare pods are emitted, so they have their own references.
+		node, err = visitPodSpecReferences(visitor, object, object, node)
+		if err != nil {
+			return GraphNode{}, err
+		}
+	}
+
 	hash, err := stringMapHash(object.GetLabels())
 	if err != nil {
 		return GraphNode{}, err
@@ -60,18 +73,12 @@ func (p *PodResourceVisitor) Visit(object *unstructured.Unstructured, node Graph
 	podLabels := labels.Set(object.GetLabels())
 
 	for _, service := range services {
-		serviceSelector, found, err := unstructured.NestedStringMap(service.Object, "spec", "selector")
+		selector, ok, err := serviceSelector(service)
 		if err != nil {
 			return GraphNode{}, err
 		}
 
-		if !found {
-			continue
-		}
-
-		set := labels.SelectorFromSet(serviceSelector)
-
-		if !set.Matches(podLabels) {
+		if !ok || !selector.Matches(podLabels) {
 			continue
 		}
 
@@ -0,0 +1,191 @@
+package rvnodegen
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// visitPodSpecReferences visits the service account, config maps, secrets and persistent volume
+// claims a pod uses through its spec, volumes, image pull secrets and its containers'
+// environment. Edges to them are added to the node of the pod or its controller, and missing
+// referents that aren't optional are emitted as placeholders.
+func visitPodSpecReferences(visitor *Visitor, source, pod *unstructured.Unstructured, node GraphNode) (GraphNode, error) {
+	references, err := podSpecReferences(pod)
+	if err != nil {
+		return GraphNode{}, fmt.Errorf("find pod spec references: %w", err)
+	}
+
+	for _, ref := range references {
+		object, ok, err := visitor.visitEdgeReference(pod, ref)
+		if err != nil {
+			return GraphNode{}, err
+		}
+
+		if !ok {
+			continue
+		}
+
+		provenance := newPodTemplateProvenance("Pod", ref.field, source, pod, ref.verb, object, ref.detail)
+		node = addEdge(node, string(object.GetUID()), ref.relation, ref.attributes, provenance)
+	}
+
+	return node, nil
+}
+
+// podSpecReferences returns the references a pod's spec makes to objects in its namespace.
+func podSpecReferences(pod *unstructured.Unstructured) ([]edgeReference, error) {
+	namespace := pod.GetNamespace()
+
+	var references []edgeReference
+
+	// serviceAccount is the deprecated alias of serviceAccountName.
+	serviceAccountName, _, _ := unstructured.NestedString(pod.Object, "spec", "serviceAccountName")
+	if serviceAccountName == "" {
+		serviceAccountName, _, _ = unstructured.NestedString(pod.Object, "spec", "serviceAccount")
+	}
+	if serviceAccountName != "" {
+		references = append(references, edgeReference{gvk: serviceAccountGVK, namespace: namespace, name: serviceAccountName,
+			relation: EdgeRelationServiceAccount, attributes: map[string]string{"serviceAccountName": serviceAccountName},
+			field: "spec.serviceAccountName", verb: "runs as"})
+	}
+
+	volumes, _, err := unstructured.NestedSlice(pod.Object, "spec", "volumes")
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range volumes {
+		volume, ok := volumes[i].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		volumeName, _, _ := unstructured.NestedString(volume, "name")
+		attributes := map[string]string{"volume": volumeName}
+		volumeRef := func(gvk schema.GroupVersionKind, name string, optional bool) edgeReference {
+			return edgeReference{gvk: gvk, namespace: namespace, name: name, relation: EdgeRelationVolume, attributes: attributes,
+				field: "spec.volumes", verb: "mounts", detail: "volume " + volumeName, optional: optional}
+		}
+
+		if name, _, _ := unstructured.NestedString(volume, "configMap", "name"); name != "" {
+			references = append(references, volumeRef(configMapGVK, name, isOptionalReference(volume, "configMap")))
+		}
+		if name, _, _ := unstructured.NestedString(volume, "secret", "secretName"); name != "" {
+			references = append(references, volumeRef(secretGVK, name, isOptionalReference(volume, "secret")))
+		}
+		if name, _, _ := unstructured.NestedString(volume, "persistentVolumeClaim", "claimName"); name != "" {
+			references = append(references, volumeRef(persistentVolumeClaimGVK, name, false))
+		}
+
+		sources, _, _ := unstructured.NestedSlice(volume, "projected", "sources")
+		for j := range sources {
+			source, ok := sources[j].(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			if name, _, _ := unstructured.NestedString(source, "configMap", "name"); name != "" {
+				references = append(references, volumeRef(configMapGVK, name, isOptionalReference(source, "configMap")))
+			}
+			if name, _, _ := unstructured.NestedString(source, "secret", "name"); name != "" {
+				references = append(references, volumeRef(secretGVK, name, isOptionalReference(source, "secret")))
+			}
+		}
+	}
+
+	pullSecrets, _, err := unstructured.NestedSlice(pod.Object, "spec", "imagePullSecrets")
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range pullSecrets {
+		pullSecret, ok := pullSecrets[i].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		if name, _, _ := unstructured.NestedString(pullSecret, "name"); name != "" {
+			references = append(references, edgeReference{gvk: secretGVK, namespace: namespace, name: name,
+				relation: EdgeRelationImagePullSecret, attributes: map[string]string{"field": "imagePullSecrets"},
+				field: "spec.imagePullSecrets", verb: "pulls images with"})
+		}
+	}
+
+	for _, field := range []string{"initContainers", "containers"} {
+		containers, _, err := unstructured.NestedSlice(pod.Object, "spec", field)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range containers {
+			container, ok := containers[i].(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			references = append(references, containerEnvReferences(namespace, "spec."+field, container)...)
+		}
+	}
+
+	return references, nil
+}
+
+func containerEnvReferences(namespace, field string, container map[string]interface{}) []edgeReference {
+	var references []edgeReference
+
+	containerName, _, _ := unstructured.NestedString(container, "name")
+
+	env, _, _ := unstructured.NestedSlice(container, "env")
+	for i := range env {
+		envVar, ok := env[i].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		envName, _, _ := unstructured.NestedString(envVar, "name")
+		attributes := map[string]string{"container": containerName, "env": envName}
+		envRef := func(gvk schema.GroupVersionKind, name string, optional bool) edgeReference {
+			return edgeReference{gvk: gvk, namespace: namespace, name: name, relation: EdgeRelationEnv, attributes: attributes,
+				field: field + ".env", verb: "references", detail: fmt.Sprintf("env %s in container %s", envName, containerName),
+				optional: optional}
+		}
+
+		if name, _, _ := unstructured.NestedString(envVar, "valueFrom", "configMapKeyRef", "name"); name != "" {
+			references = append(references, envRef(configMapGVK, name, isOptionalReference(envVar, "valueFrom", "configMapKeyRef")))
+		}
+		if name, _, _ := unstructured.NestedString(envVar, "valueFrom", "secretKeyRef", "name"); name != "" {
+			references = append(references, envRef(secretGVK, name, isOptionalReference(envVar, "valueFrom", "secretKeyRef")))
+		}
+	}
+
+	envFrom, _, _ := unstructured.NestedSlice(container, "envFrom")
+	for i := range envFrom {
+		source, ok := envFrom[i].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		attributes := map[string]string{"container": containerName, "envFrom": "true"}
+		envFromRef := func(gvk schema.GroupVersionKind, name string, optional bool) edgeReference {
+			return edgeReference{gvk: gvk, namespace: namespace, name: name, relation: EdgeRelationEnv, attributes: attributes,
+				field: field + ".envFrom", verb: "loads", detail: "envFrom in container " + containerName, optional: optional}
+		}
+
+		if name, _, _ := unstructured.NestedString(source, "configMapRef", "name"); name != "" {
+			references = append(references, envFromRef(configMapGVK, name, isOptionalReference(source, "configMapRef")))
+		}
+		if name, _, _ := unstructured.NestedString(source, "secretRef", "name"); name != "" {
+			references = append(references, envFromRef(secretGVK, name, isOptionalReference(source, "secretRef")))
+		}
+	}
+
+	return references
+}
+
+// isOptionalReference returns true if the reference at fields is marked optional.
+func isOptionalReference(m map[string]interface{}, fields ...string) bool {
+	optional, _, _ := unstructured.NestedBool(m, append(fields, "optional")...)
+	return optional
+}
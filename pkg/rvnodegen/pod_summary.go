@@ -0,0 +1,147 @@
+package rvnodegen
+
+import (
+	"fmt"
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// PodSummary summarizes the pods controlled by an object.
+type PodSummary struct {
+	// Total is the number of pods.
+	Total int `json:"total"`
+	// Phases are pod counts by phase.
+	Phases map[string]int `json:"phases"`
+	// Ready is the number of ready pods.
+	Ready int `json:"ready"`
+	// NotReady is the number of pods that are not ready.
+	NotReady int `json:"notReady"`
+	// Restarts is the total number of container restarts.
+	Restarts int64 `json:"restarts"`
+	// Unhealthy are the names of pods that are not healthy.
+	Unhealthy []string `json:"unhealthy,omitempty"`
+}
+
+// HealthStatus returns the health status for the summarized pods. The pods are failed if none of
+// them are healthy.
+func (ps PodSummary) HealthStatus() HealthStatusType {
+	switch {
+	case len(ps.Unhealthy) == 0:
+		return HealthStatusTypeHealthy
+	case len(ps.Unhealthy) == ps.Total:
+		return HealthStatusTypeFailure
+	default:
+		return HealthStatusTypeDegraded
+	}
+}
+
+// isSummarizedPod returns true if a pod is summarized by its controller's node instead of being
+// emitted. Controlled pods are found in their controller's namespace, so pods controlled by
+// cluster scoped objects, like the mirror pods of static pods, are emitted like bare pods.
+func isSummarizedPod(lister Lister, pod *unstructured.Unstructured) (bool, error) {
+	ref := metav1.GetControllerOf(pod)
+	if ref == nil {
+		return false, nil
+	}
+
+	gvk, err := ownerGVK(*ref)
+	if err != nil {
+		return false, err
+	}
+
+	namespace, err := scopedNamespace(lister, gvk, pod.GetNamespace())
+	if err != nil {
+		return false, err
+	}
+
+	return namespace != "", nil
+}
+
+func summarizePods(pods []*unstructured.Unstructured) (PodSummary, error) {
+	summary := PodSummary{
+		Phases: map[string]int{},
+	}
+
+	for _, pod := range pods {
+		status, err := readPodStatus(pod)
+		if err != nil {
+			return PodSummary{}, fmt.Errorf("read status for pod %s: %w", pod.GetName(), err)
+		}
+
+		summary.Total++
+		summary.Phases[status.phase]++
+		summary.Restarts += status.restarts
+
+		if status.ready {
+			summary.Ready++
+		} else {
+			summary.NotReady++
+		}
+
+		if status.healthStatus() != HealthStatusTypeHealthy {
+			summary.Unhealthy = append(summary.Unhealthy, pod.GetName())
+		}
+	}
+
+	sort.Strings(summary.Unhealthy)
+
+	return summary, nil
+}
+
+type podStatus struct {
+	phase        string
+	ready        bool
+	restarts     int64
+	crashLooping bool
+}
+
+func (ps podStatus) healthStatus() HealthStatusType {
+	switch {
+	case ps.phase == "Failed" || ps.phase == "Unknown" || ps.crashLooping:
+		return HealthStatusTypeFailure
+	case ps.phase == "Succeeded" || (ps.phase == "Running" && ps.ready):
+		return HealthStatusTypeHealthy
+	default:
+		return HealthStatusTypeDegraded
+	}
+}
+
+func readPodStatus(pod *unstructured.Unstructured) (podStatus, error) {
+	phase, _, err := unstructured.NestedString(pod.Object, "status", "phase")
+	if err != nil {
+		return podStatus{}, err
+	}
+
+	ready, found, err := findCondition(pod, "Ready")
+	if err != nil {
+		return podStatus{}, err
+	}
+
+	status := podStatus{
+		phase: phase,
+		ready: found && ready.Status == "True",
+	}
+
+	containerStatuses, _, err := unstructured.NestedSlice(pod.Object, "status", "containerStatuses")
+	if err != nil {
+		return podStatus{}, err
+	}
+
+	for i := range containerStatuses {
+		containerStatus, ok := containerStatuses[i].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		restarts, _, _ := unstructured.NestedInt64(containerStatus, "restartCount")
+		status.restarts += restarts
+
+		if reason, _, _ := unstructured.NestedString(containerStatus, "state", "waiting", "reason"); reason == "CrashLoopBackOff" {
+			status.crashLooping = true
+		}
+	}
+
+	return status, nil
+}
@@ -0,0 +1,72 @@
+package rvnodegen
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ReplicaSetResourceVisitor visits a replica set. It adds the deployment revision the replica
+// set belongs to.
+type ReplicaSetResourceVisitor struct {
+	lister Lister
+}
+
+var _ ResourceVisitor = &ReplicaSetResourceVisitor{}
+
+// NewReplicaSetResourceVisitor creates an instance of ReplicaSetResourceVisitor.
+func NewReplicaSetResourceVisitor(lister Lister) *ReplicaSetResourceVisitor {
+	r := &ReplicaSetResourceVisitor{
+		lister: lister,
+	}
+	return r
+}
+
+// Name is the name of the resource visitor.
+func (r *ReplicaSetResourceVisitor) Name() string {
+	return "ReplicaSet"
+}
+
+// Matches returns a group/version/kind that this resource visitor matches.
+func (r *ReplicaSetResourceVisitor) Matches(gvk schema.GroupVersionKind) bool {
+	return replicaSetGVK.String() == gvk.String()
+}
+
+// Visit visits a replica set resource.
+func (r *ReplicaSetResourceVisitor) Visit(object *unstructured.Unstructured, node GraphNode, visitor *Visitor) (GraphNode, error) {
+	ref := metav1.GetControllerOf(object)
+	if ref == nil || ref.Kind != deploymentGVK.Kind {
+		return node, nil
+	}
+
+	deployment, err := r.lister.ByNamespace(object.GetNamespace()).Get(deploymentGVK, ref.Name)
+	if err != nil {
+		if isUnavailable(err) {
+			return node, nil
+		}
+		return GraphNode{}, fmt.Errorf("get deployment %s: %w", ref.Name, err)
+	}
+
+	deploymentRevision, err := revisionAnnotation(deployment)
+	if err != nil {
+		return GraphNode{}, err
+	}
+
+	revision, err := readReplicaSetRevision(object, deploymentRevision)
+	if err != nil {
+		return GraphNode{}, err
+	}
+
+	if node.Extra == nil {
+		node.Extra = map[string]interface{}{}
+	}
+	node.Extra["revision"] = revision.Revision
+	node.Extra["podTemplateHash"] = revision.PodTemplateHash
+	node.Extra["replicas"] = revision.Replicas
+	node.Extra["readyReplicas"] = revision.ReadyReplicas
+	node.Extra["current"] = revision.Current
+
+	return node, nil
+}
@@ -0,0 +1,147 @@
+package rvnodegen
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	deploymentRevisionAnnotation = "deployment.kubernetes.io/revision"
+	podTemplateHashLabel         = "pod-template-hash"
+)
+
+// ReplicaSetRevision is a revision of a deployment's pod template.
+type ReplicaSetRevision struct {
+	// Name is the name of the replica set.
+	Name string `json:"name"`
+	// Revision is the deployment revision.
+	Revision int64 `json:"revision"`
+	// PodTemplateHash is the hash of the pod template.
+	PodTemplateHash string `json:"podTemplateHash,omitempty"`
+	// Replicas is the desired number of replicas.
+	Replicas int64 `json:"replicas"`
+	// CurrentReplicas is the number of pods running for the revision.
+	CurrentReplicas int64 `json:"currentReplicas"`
+	// ReadyReplicas is the number of ready replicas.
+	ReadyReplicas int64 `json:"readyReplicas"`
+	// Current is true if this is the deployment's current revision.
+	Current bool `json:"current"`
+	// Collapsed is true if the revision is not shown as a node.
+	Collapsed bool `json:"collapsed,omitempty"`
+}
+
+// Rollout is the rollout history of a deployment.
+type Rollout struct {
+	// CurrentRevision is the deployment's current revision.
+	CurrentRevision int64 `json:"currentRevision"`
+	// InProgress is true if pods from old revisions remain or the current revision is not ready.
+	InProgress bool `json:"inProgress"`
+	// NewPods is the number of pods for the current revision.
+	NewPods int64 `json:"newPods"`
+	// OldPods is the number of pods for old revisions.
+	OldPods int64 `json:"oldPods"`
+	// Revisions are the deployment's revisions, newest first.
+	Revisions []ReplicaSetRevision `json:"revisions"`
+}
+
+func readReplicaSetRevision(replicaSet *unstructured.Unstructured, deploymentRevision int64) (ReplicaSetRevision, error) {
+	revision, err := revisionAnnotation(replicaSet)
+	if err != nil {
+		return ReplicaSetRevision{}, err
+	}
+
+	replicas, _, err := unstructured.NestedInt64(replicaSet.Object, "spec", "replicas")
+	if err != nil {
+		return ReplicaSetRevision{}, err
+	}
+
+	currentReplicas, _, err := unstructured.NestedInt64(replicaSet.Object, "status", "replicas")
+	if err != nil {
+		return ReplicaSetRevision{}, err
+	}
+
+	readyReplicas, _, err := unstructured.NestedInt64(replicaSet.Object, "status", "readyReplicas")
+	if err != nil {
+		return ReplicaSetRevision{}, err
+	}
+
+	r := ReplicaSetRevision{
+		Name:            replicaSet.GetName(),
+		Revision:        revision,
+		PodTemplateHash: replicaSet.GetLabels()[podTemplateHashLabel],
+		Replicas:        replicas,
+		CurrentReplicas: currentReplicas,
+		ReadyReplicas:   readyReplicas,
+		Current:         revision == deploymentRevision,
+	}
+
+	return r, nil
+}
+
+// isScaledDown returns true if an old revision has no pods.
+func (r ReplicaSetRevision) isScaledDown() bool {
+	return !r.Current && r.Replicas == 0 && r.CurrentReplicas == 0
+}
+
+func buildRollout(deployment *unstructured.Unstructured, replicaSets []*unstructured.Unstructured, expand bool) (Rollout, error) {
+	deploymentRevision, err := revisionAnnotation(deployment)
+	if err != nil {
+		return Rollout{}, err
+	}
+
+	desired, found, err := unstructured.NestedInt64(deployment.Object, "spec", "replicas")
+	if err != nil {
+		return Rollout{}, err
+	}
+	if !found {
+		desired = 1
+	}
+
+	rollout := Rollout{
+		CurrentRevision: deploymentRevision,
+	}
+
+	var newReady int64
+
+	for _, replicaSet := range replicaSets {
+		r, err := readReplicaSetRevision(replicaSet, deploymentRevision)
+		if err != nil {
+			return Rollout{}, fmt.Errorf("read revision for replica set %s: %w", replicaSet.GetName(), err)
+		}
+
+		if r.Current {
+			rollout.NewPods += r.CurrentReplicas
+			newReady += r.ReadyReplicas
+		} else {
+			rollout.OldPods += r.CurrentReplicas
+		}
+
+		r.Collapsed = !expand && r.isScaledDown()
+		rollout.Revisions = append(rollout.Revisions, r)
+	}
+
+	sort.Slice(rollout.Revisions, func(i, j int) bool {
+		return rollout.Revisions[i].Revision > rollout.Revisions[j].Revision
+	})
+
+	rollout.InProgress = rollout.OldPods > 0 || newReady < desired
+
+	return rollout, nil
+}
+
+func revisionAnnotation(object *unstructured.Unstructured) (int64, error) {
+	value, ok := object.GetAnnotations()[deploymentRevisionAnnotation]
+	if !ok {
+		return 0, nil
+	}
+
+	revision, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parse revision %q: %w", value, err)
+	}
+
+	return revision, nil
+}
@@ -19,7 +19,14 @@ type ResourceVisitor interface {
 func ResourceVisitorsFactory(lister Lister) []ResourceVisitor {
 	return []ResourceVisitor{
 		NewPodResourceVisitor(lister),
+		NewDeploymentResourceVisitor(lister),
+		NewReplicaSetResourceVisitor(lister),
 		NewServiceAccountVisitor(lister),
 		NewServiceResourceVisitor(lister),
+		NewIngressResourceVisitor(lister),
+		NewPersistentVolumeVisitor(lister),
+		NewClusterRoleBindingVisitor(lister),
+		NewArgoCDVisitor(lister),
+		NewFluxVisitor(lister),
 	}
 }
@@ -0,0 +1,198 @@
+package rvnodegen
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// ResponseVersion is the version of the node response format.
+type ResponseVersion string
+
+const (
+	// ResponseVersionV1 describes relationships with targets.
+	ResponseVersionV1 ResponseVersion = "v1"
+	// ResponseVersionV2 describes relationships with typed edges.
+	ResponseVersionV2 ResponseVersion = "v2"
+)
+
+func parseResponseVersion(s string) (ResponseVersion, error) {
+	switch ResponseVersion(s) {
+	case "", ResponseVersionV1:
+		return ResponseVersionV1, nil
+	case ResponseVersionV2:
+		return ResponseVersionV2, nil
+	default:
+		return "", fmt.Errorf("unknown response version %q", s)
+	}
+}
+
+// ResponseOptions configure how nodes are returned to clients.
+type ResponseOptions struct {
+	// Version is the response format version.
+	Version ResponseVersion
+	// MetadataFields are the node metadata fields to include. Metadata is omitted if there are
+	// no fields.
+	MetadataFields []MetadataField
+	// AnnotationKeys select the annotations included with metadata.
+	AnnotationKeys []string
+	// Filter selects the nodes to return. All nodes are returned if it is nil.
+	Filter *Filter
+	// Hops is the number of hops of neighbors of filtered nodes to return.
+	Hops int
+	// Layout is the server side layout to apply. Clients lay out nodes if it is empty.
+	Layout LayoutAlgorithm
+}
+
+// responseOptionsFromQuery creates response options from query parameters. Metadata fields are
+// comma separated in the metadata parameter, and the annotation parameter can be repeated.
+func responseOptionsFromQuery(values url.Values) (ResponseOptions, error) {
+	version, err := parseResponseVersion(values.Get("version"))
+	if err != nil {
+		return ResponseOptions{}, err
+	}
+
+	fields, err := parseMetadataFields(values["metadata"])
+	if err != nil {
+		return ResponseOptions{}, err
+	}
+
+	ro := ResponseOptions{
+		Version:        version,
+		MetadataFields: fields,
+		AnnotationKeys: values["annotation"],
+	}
+
+	if expression := values.Get("filter"); expression != "" {
+		ro.Filter, err = ParseFilter(expression)
+		if err != nil {
+			return ResponseOptions{}, err
+		}
+	}
+
+	if value := values.Get("hops"); value != "" {
+		ro.Hops, err = strconv.Atoi(value)
+		if err != nil || ro.Hops < 0 {
+			return ResponseOptions{}, fmt.Errorf("invalid hops %q", value)
+		}
+	}
+
+	ro.Layout, err = parseLayoutAlgorithm(values.Get("layout"))
+	if err != nil {
+		return ResponseOptions{}, err
+	}
+
+	return ro, nil
+}
+
+// responseOptionsFromPayload creates response options from a command payload.
+func responseOptionsFromPayload(payload Payload) (ResponseOptions, error) {
+	version, _ := payload["version"].(string)
+
+	v, err := parseResponseVersion(version)
+	if err != nil {
+		return ResponseOptions{}, err
+	}
+
+	metadata, err := payloadStrings(payload, "metadata")
+	if err != nil {
+		return ResponseOptions{}, err
+	}
+
+	fields, err := parseMetadataFields(metadata)
+	if err != nil {
+		return ResponseOptions{}, err
+	}
+
+	annotationKeys, err := payloadStrings(payload, "annotations")
+	if err != nil {
+		return ResponseOptions{}, err
+	}
+
+	ro := ResponseOptions{
+		Version:        v,
+		MetadataFields: fields,
+		AnnotationKeys: annotationKeys,
+	}
+
+	if expression, _ := payload["filter"].(string); expression != "" {
+		ro.Filter, err = ParseFilter(expression)
+		if err != nil {
+			return ResponseOptions{}, err
+		}
+	}
+
+	if hops, ok := payload["hops"].(float64); ok {
+		if hops < 0 {
+			return ResponseOptions{}, fmt.Errorf("invalid hops %v", hops)
+		}
+		ro.Hops = int(hops)
+	}
+
+	layout, _ := payload["layout"].(string)
+	ro.Layout, err = parseLayoutAlgorithm(layout)
+	if err != nil {
+		return ResponseOptions{}, err
+	}
+
+	return ro, nil
+}
+
+// payloadStrings reads a string or a list of strings from a payload.
+func payloadStrings(payload Payload, key string) ([]string, error) {
+	switch v := payload[key].(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []string{v}, nil
+	case []interface{}:
+		var out []string
+		for i := range v {
+			s, ok := v[i].(string)
+			if !ok {
+				return nil, fmt.Errorf("payload %s must be strings", key)
+			}
+			out = append(out, s)
+		}
+		return out, nil
+	default:
+		return nil, fmt.Errorf("payload %s must be a string or a list of strings", key)
+	}
+}
+
+// ApplyWithLayout shapes nodes for a response and lays them out if a layout was requested. The
+// layout is seeded with its previous positions.
+func (ro ResponseOptions) ApplyWithLayout(nodes []GraphNode, layout *LayeredLayout) []GraphNode {
+	nodes = ro.Apply(nodes)
+
+	if ro.Layout == LayoutLayered {
+		nodes = layout.Layout(nodes)
+	}
+
+	return nodes
+}
+
+// Apply shapes nodes for a response. Nodes are filtered first. Version 1 responses only have
+// targets, and version 2 responses only have edges. Metadata is limited to the requested fields.
+func (ro ResponseOptions) Apply(nodes []GraphNode) []GraphNode {
+	nodes = FilterNodes(nodes, ro.Filter, ro.Hops)
+
+	out := make([]GraphNode, len(nodes))
+
+	for i := range nodes {
+		node := nodes[i]
+
+		switch ro.Version {
+		case ResponseVersionV2:
+			node.Targets = nil
+		default:
+			node.Edges = nil
+		}
+
+		node.Metadata = node.Metadata.selectFields(ro.MetadataFields, ro.AnnotationKeys)
+
+		out[i] = node
+	}
+
+	return out
+}
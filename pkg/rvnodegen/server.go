@@ -7,55 +7,83 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"regexp"
+	"strings"
 	"syscall"
 	"time"
 
-	restclient "k8s.io/client-go/rest"
-	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/util/homedir"
 
 	"github.com/bryanl/rv-node-gen/internal/log"
 )
 
+var unsafeCacheDirCharacters = regexp.MustCompile(`[^(\w/.)]`)
+
 // Server is the node gen server.
 type Server struct {
-	addr           string
-	kubeConfigPath string
+	addr   string
+	newAPI func(ctx context.Context) (*API, error)
 }
 
-// NewServer creates an instance of Server.
-func NewServer(kubeConfigPath, addr string) *Server {
+// NewServer creates an instance of Server for the cluster selected by a kube config. Options are used
+// when building nodes.
+func NewServer(kubeConfig KubeConfig, addr string, options ...Option) *Server {
 	s := &Server{
-		addr:           addr,
-		kubeConfigPath: kubeConfigPath,
+		addr: addr,
+		newAPI: func(ctx context.Context) (*API, error) {
+			log.From(ctx).Info("Initializing informer manager")
+			lister, err := NewClusterLister(kubeConfig, options...)
+			if err != nil {
+				return nil, err
+			}
+			log.From(ctx).Info("Informer initialized")
+
+			return NewAPI(lister, options...), nil
+		},
 	}
 	return s
 }
 
-// Run runs the server.
-func (s *Server) Run(ctx context.Context) error {
-	logger := log.From(ctx)
-
-	restConfig, err := initRestConfig(s.kubeConfigPath)
-	if err != nil {
-		return fmt.Errorf("initialize REST config: %w", err)
+// NewListerServer creates an instance of Server that serves objects from a lister, such as a
+// manifest or snapshot lister. Options are used when building nodes.
+func NewListerServer(lister Lister, addr string, options ...Option) *Server {
+	s := &Server{
+		addr: addr,
+		newAPI: func(ctx context.Context) (*API, error) {
+			return NewAPI(lister, options...), nil
+		},
 	}
+	return s
+}
 
-	restConfig.QPS = 200
-	restConfig.Burst = 400
-
-	client, err := NewClient(restConfig, DiscoveryCacheDir("/Users/bryanl/.kube/cache/discovery"))
-	if err != nil {
-		return fmt.Errorf("initialize cluster client: %w", err)
+// NewMultiClusterServer creates an instance of Server for contexts in a kube config. If no
+// contexts are supplied, all contexts are served. Clusters connect in the background, so the
+// server starts before they are synced. Options are used when building nodes.
+func NewMultiClusterServer(kubeConfig KubeConfig, contexts []string, addr string, options ...Option) *Server {
+	s := &Server{
+		addr: addr,
+		newAPI: func(ctx context.Context) (*API, error) {
+			clusters, err := NewClusterManager(kubeConfig, contexts...)
+			if err != nil {
+				return nil, err
+			}
+			clusters.Start(ctx)
+
+			return NewMultiClusterAPI(clusters, options...), nil
+		},
 	}
+	return s
+}
+
+// Run runs the server.
+func (s *Server) Run(ctx context.Context) error {
+	logger := log.From(ctx)
 
-	logger.Info("Initializing informer manager")
-	informerManager, err := NewInformerManager(client)
+	api, err := s.newAPI(ctx)
 	if err != nil {
-		return fmt.Errorf("create informer factory: %w", err)
+		return err
 	}
-	logger.Info("Informer initialized")
-
-	api := NewAPI(informerManager.Lister())
 
 	srv := &http.Server{
 		Addr:    s.addr,
@@ -113,6 +141,53 @@ func configureCORS(next http.Handler) http.Handler {
 	})
 }
 
-func initRestConfig(kubeConfigPath string) (*restclient.Config, error) {
-	return clientcmd.BuildConfigFromFlags("", kubeConfigPath)
+// NewClusterLister creates a lister for the cluster selected by a kube config. It returns once
+// the informer caches are synced. Options configure the cluster's informer manager.
+func NewClusterLister(kubeConfig KubeConfig, options ...Option) (Lister, error) {
+	informerManager, err := NewClusterInformerManager(kubeConfig, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	return informerManager.Lister(), nil
+}
+
+// NewClusterInformerManager creates an informer manager for the cluster selected by a kube
+// config. It returns once the informer caches are synced. Options configure the informer manager.
+func NewClusterInformerManager(kubeConfig KubeConfig, options ...Option) (*InformerManager, error) {
+	restConfig, err := kubeConfig.RESTConfig()
+	if err != nil {
+		return nil, fmt.Errorf("initialize REST config: %w", err)
+	}
+
+	restConfig.QPS = 200
+	restConfig.Burst = 400
+
+	client, err := NewClient(restConfig, DiscoveryCacheDir(discoveryCacheDir(restConfig.Host)))
+	if err != nil {
+		return nil, fmt.Errorf("initialize cluster client: %w", err)
+	}
+
+	// users who can't list namespaces are checked in the context's namespace.
+	namespace, err := kubeConfig.DefaultNamespace()
+	if err != nil {
+		return nil, err
+	}
+
+	options = append([]Option{AccessNamespaces(namespace)}, options...)
+
+	informerManager, err := NewInformerManager(client, options...)
+	if err != nil {
+		return nil, fmt.Errorf("create informer factory: %w", err)
+	}
+
+	return informerManager, nil
+}
+
+// discoveryCacheDir is the discovery cache directory for an API server. Like kubectl, each
+// server has its own directory, so clusters don't share cached discovery.
+func discoveryCacheDir(host string) string {
+	schemelessHost := strings.TrimPrefix(strings.TrimPrefix(host, "https://"), "http://")
+	safeHost := unsafeCacheDirCharacters.ReplaceAllString(schemelessHost, "_")
+	return filepath.Join(homedir.HomeDir(), ".kube", "cache", "discovery", safeHost)
 }
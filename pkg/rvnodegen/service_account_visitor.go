@@ -30,37 +30,56 @@ func (s *ServiceAccountVisitor) Matches(gvk schema.GroupVersionKind) bool {
 	return serviceAccountGVK.String() == gvk.String()
 }
 
-// Visit visits a service account resource.
+// Visit visits a service account resource. Edges are added to its token and image pull secrets.
 func (s *ServiceAccountVisitor) Visit(object *unstructured.Unstructured, node GraphNode, visitor *Visitor) (GraphNode, error) {
-	secrets, found, err := unstructured.NestedSlice(object.Object, "secrets")
+	references, err := serviceAccountReferences(object)
 	if err != nil {
 		return GraphNode{}, err
 	}
 
-	if !found {
-		return node, nil
+	return visitEdgeReferences(visitor, s.Name(), object, references, node)
+}
+
+// serviceAccountReferences returns the references a service account makes to its token and image
+// pull secrets.
+func serviceAccountReferences(object *unstructured.Unstructured) ([]edgeReference, error) {
+	var references []edgeReference
+
+	secretFields := []struct {
+		field    string
+		relation EdgeRelation
+		verb     string
+	}{
+		{field: "secrets", relation: EdgeRelationServiceAccount, verb: "uses"},
+		{field: "imagePullSecrets", relation: EdgeRelationImagePullSecret, verb: "pulls images with"},
 	}
 
-	for i := range secrets {
-		secretConfig := secrets[i].(map[string]interface{})
-		name, _, err := unstructured.NestedString(secretConfig, "name")
+	for _, secretField := range secretFields {
+		secrets, _, err := unstructured.NestedSlice(object.Object, secretField.field)
 		if err != nil {
-			return GraphNode{}, err
+			return nil, err
 		}
 
-		secret, err := s.lister.
-			ByNamespace(object.GetNamespace()).
-			Get(secretGVK, name)
-		if err != nil {
-			return GraphNode{}, err
-		}
+		for i := range secrets {
+			secret, ok := secrets[i].(map[string]interface{})
+			if !ok {
+				continue
+			}
 
-		if err := visitor.Visit(false, secret); err != nil {
-			return GraphNode{}, err
-		}
+			name, _, err := unstructured.NestedString(secret, "name")
+			if err != nil {
+				return nil, err
+			}
 
-		node.Targets = append(node.Targets, string(secret.GetUID()))
+			if name == "" {
+				continue
+			}
+
+			references = append(references, edgeReference{gvk: secretGVK, namespace: object.GetNamespace(), name: name,
+				relation: secretField.relation, attributes: map[string]string{"field": secretField.field},
+				field: secretField.field, verb: secretField.verb})
+		}
 	}
 
-	return node, nil
+	return references, nil
 }
@@ -1,6 +1,8 @@
 package rvnodegen
 
 import (
+	"fmt"
+
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -31,16 +33,15 @@ func (s *ServiceResourceVisitor) Matches(gvk schema.GroupVersionKind) bool {
 	return serviceGVK.String() == gvk.String()
 }
 
-// Visit visits a service resource.
+// Visit visits a service resource. It targets the owners of the pods it selects, or the pods
+// themselves if they are not summarized by a controller.
 func (s *ServiceResourceVisitor) Visit(object *unstructured.Unstructured, node GraphNode, visitor *Visitor) (GraphNode, error) {
-	serviceSelector, _, err := unstructured.NestedStringMap(object.Object, "spec", "selector")
-	if err != nil {
-		return GraphNode{}, err
+	selector, ok, err := serviceSelector(object)
+	if err != nil || !ok {
+		return node, err
 	}
 
-	set := labels.SelectorFromSet(serviceSelector)
-
-	pods, err := s.lister.ByNamespace(object.GetNamespace()).List(podGVK, set)
+	pods, err := selectedPods(s.lister, object)
 	if err != nil {
 		return GraphNode{}, err
 	}
@@ -48,19 +49,28 @@ func (s *ServiceResourceVisitor) Visit(object *unstructured.Unstructured, node G
 	ownersByID := map[string]*unstructured.Unstructured{}
 
 	for _, pod := range pods {
-		for _, ref := range pod.GetOwnerReferences() {
-			gv, err := schema.ParseGroupVersion(ref.APIVersion)
-			if err != nil {
+		summarized, err := isSummarizedPod(s.lister, pod)
+		if err != nil {
+			return GraphNode{}, err
+		}
+
+		if !summarized {
+			if err := visitor.Visit(false, pod); err != nil {
 				return GraphNode{}, err
 			}
 
-			gvk := schema.GroupVersionKind{
-				Group:   gv.Group,
-				Version: gv.Version,
-				Kind:    ref.Kind,
+			node = addEdge(node, string(pod.GetUID()), EdgeRelationSelector, map[string]string{
+				"selector": selector.String(),
+			}, newEdgeProvenance(s.Name(), "spec.selector", object, "selects", pod, selector.String()))
+		}
+
+		for _, ref := range pod.GetOwnerReferences() {
+			gvk, err := ownerGVK(ref)
+			if err != nil {
+				return GraphNode{}, err
 			}
 
-			owner, err := s.lister.ByNamespace(object.GetNamespace()).Get(gvk, ref.Name)
+			owner, err := visitor.visitReference(gvk, object.GetNamespace(), ref.Name)
 			if err != nil {
 				return GraphNode{}, err
 			}
@@ -69,16 +79,41 @@ func (s *ServiceResourceVisitor) Visit(object *unstructured.Unstructured, node G
 		}
 	}
 
-	var owners []*unstructured.Unstructured
-
 	for id, owner := range ownersByID {
-		node.Targets = append(node.Targets, id)
-		owners = append(owners, owner)
+		node = addEdge(node, id, EdgeRelationSelector, map[string]string{
+			"selector": selector.String(),
+		}, newEdgeProvenance(s.Name(), "spec.selector", object, "selects pods of", owner, selector.String()))
 	}
 
-	if err := visitor.Visit(false, owners...); err != nil {
-		return GraphNode{}, err
+	return node, nil
+}
+
+// serviceSelector returns the selector for the pods a service routes to. It returns false for
+// services without a selector, whose endpoints are managed separately.
+func serviceSelector(service *unstructured.Unstructured) (labels.Selector, bool, error) {
+	selector, _, err := unstructured.NestedStringMap(service.Object, "spec", "selector")
+	if err != nil {
+		return nil, false, err
 	}
 
-	return node, nil
+	if len(selector) == 0 {
+		return nil, false, nil
+	}
+
+	return labels.SelectorFromSet(selector), true, nil
+}
+
+// selectedPods returns the pods a service selects.
+func selectedPods(lister Lister, service *unstructured.Unstructured) ([]*unstructured.Unstructured, error) {
+	selector, ok, err := serviceSelector(service)
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	pods, err := lister.ByNamespace(service.GetNamespace()).List(podGVK, selector)
+	if err != nil {
+		return nil, fmt.Errorf("list pods: %w", err)
+	}
+
+	return pods, nil
 }
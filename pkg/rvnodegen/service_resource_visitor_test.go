@@ -0,0 +1,56 @@
+package rvnodegen
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestServiceResourceVisitor_Visit(t *testing.T) {
+	_, replicaSet, controlled := newTestDeployment("default", "api", "config")
+	controlled.SetLabels(map[string]string{"app": "api"})
+
+	bare := newTestObject(podGVK, "default", "debug")
+	bare.SetLabels(map[string]string{"app": "api"})
+
+	unselected := newTestObject(podGVK, "default", "other")
+	unselected.SetLabels(map[string]string{"app": "other"})
+
+	service := newTestObject(serviceGVK, "default", "api")
+	service.Object["spec"] = map[string]interface{}{
+		"selector": map[string]interface{}{"app": "api"},
+	}
+
+	lister := newFakeClusterLister(t, fakeWorkloadResources, replicaSet, controlled, bare, unselected, service)
+
+	nodes := visitWithResourceVisitor(t, lister, NewServiceResourceVisitor(lister),
+		[]*unstructured.Unstructured{service})
+
+	node, ok := nodes[string(service.GetUID())]
+	if !ok {
+		t.Fatalf("service node was not emitted")
+	}
+
+	selected := map[string]bool{}
+	for _, edge := range node.Edges {
+		if edge.Relation == EdgeRelationSelector {
+			selected[edge.Target] = true
+		}
+	}
+
+	for _, id := range []string{string(replicaSet.GetUID()), string(bare.GetUID())} {
+		if !selected[id] {
+			t.Errorf("service does not select %s, selected = %v", id, selected)
+		}
+
+		if _, ok := nodes[id]; !ok {
+			t.Errorf("node %s was not emitted", id)
+		}
+	}
+
+	for _, id := range []string{string(controlled.GetUID()), string(unselected.GetUID())} {
+		if selected[id] {
+			t.Errorf("service selects %s", id)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package rvnodegen
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// SkippedResourceReporter reports the resources a lister skipped because the user can't read
+// them.
+type SkippedResourceReporter interface {
+	SkippedResources() ([]SkippedResource, error)
+}
+
+type skippedResourcesResponse struct {
+	Resources []SkippedResource `json:"resources"`
+}
+
+// SkippedResourceHandler is a HTTP handler that lists the resources skipped because the user
+// can't list and watch them across namespaces. If the cluster path variable is set, the
+// cluster's skipped resources are listed.
+type SkippedResourceHandler struct {
+	lister   Lister
+	clusters *ClusterManager
+}
+
+var _ http.Handler = &SkippedResourceHandler{}
+
+// NewSkippedResourceHandler creates an instance of SkippedResourceHandler. Clusters may be nil
+// if clusters are not managed.
+func NewSkippedResourceHandler(lister Lister, clusters *ClusterManager) *SkippedResourceHandler {
+	h := &SkippedResourceHandler{
+		lister:   lister,
+		clusters: clusters,
+	}
+	return h
+}
+
+// ServeHTTP serves the handler.
+func (h *SkippedResourceHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	lister := h.lister
+
+	if cluster, ok := mux.Vars(r)["cluster"]; ok && h.clusters != nil {
+		clusterLister, err := h.clusters.Lister(cluster)
+		if err != nil {
+			respondWithError(w, err, clusterErrorStatus(err))
+			return
+		}
+		lister = clusterLister
+	}
+
+	resources := []SkippedResource{}
+
+	if reporter, ok := lister.(SkippedResourceReporter); ok {
+		skipped, err := reporter.SkippedResources()
+		if err != nil {
+			respondWithError(w, err, clusterErrorStatus(err))
+			return
+		}
+		resources = append(resources, skipped...)
+	}
+
+	respondWithJSON(w, skippedResourcesResponse{Resources: resources}, http.StatusOK)
+}
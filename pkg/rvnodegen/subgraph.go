@@ -0,0 +1,282 @@
+package rvnodegen
+
+import (
+	"errors"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// DefaultSubgraphDepth is the depth used when building a subgraph without a depth.
+const DefaultSubgraphDepth = 3
+
+var (
+	// ErrObjectNotFound is returned when an object reference does not resolve to an object.
+	ErrObjectNotFound = errors.New("object not found")
+	// ErrInvalidObjectReference is returned when an object reference can't identify an object.
+	ErrInvalidObjectReference = errors.New("invalid object reference")
+)
+
+// uidLookupGVKs are the kinds searched when an object reference only has a uid and the lister
+// can't list its kinds.
+var uidLookupGVKs = []schema.GroupVersionKind{
+	podGVK, deploymentGVK, replicaSetGVK, statefulSetGVK, daemonSetGVK, jobGVK, cronJobGVK,
+	replicationControllerGVK, serviceGVK, ingressGVK, configMapGVK, secretGVK, serviceAccountGVK,
+	persistentVolumeClaimGVK, roleGVK, roleBindingGVK, argoApplicationGVK, fluxKustomizationGVK,
+	fluxHelmReleaseGVK, persistentVolumeGVK, storageClassGVK, ingressClassGVK, clusterRoleGVK,
+	clusterRoleBindingGVK, nodeGVK,
+}
+
+// ObjectReference identifies an object by api version, kind, namespace and name, or by uid.
+type ObjectReference struct {
+	APIVersion string `json:"apiVersion,omitempty"`
+	Kind       string `json:"kind,omitempty"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name,omitempty"`
+	UID        string `json:"uid,omitempty"`
+}
+
+// String returns a description of the reference.
+func (r ObjectReference) String() string {
+	if r.Name == "" {
+		return fmt.Sprintf("uid %s", r.UID)
+	}
+
+	if r.Namespace == "" {
+		return fmt.Sprintf("%s %s", r.Kind, r.Name)
+	}
+
+	return fmt.Sprintf("%s %s/%s", r.Kind, r.Namespace, r.Name)
+}
+
+// Validate returns an error if the reference cannot identify an object. A kind requires an api
+// version, e.g. v1 for core kinds.
+func (r ObjectReference) Validate() error {
+	switch {
+	case r.Name != "" && r.Kind == "":
+		return fmt.Errorf("object reference with a name requires a kind: %w", ErrInvalidObjectReference)
+	case r.Name == "" && r.UID == "":
+		return fmt.Errorf("object reference requires a kind and name or a uid: %w", ErrInvalidObjectReference)
+	case r.Kind != "" && r.APIVersion == "":
+		return fmt.Errorf("object reference with a kind requires an api version: %w", ErrInvalidObjectReference)
+	}
+
+	if r.APIVersion != "" {
+		if _, err := schema.ParseGroupVersion(r.APIVersion); err != nil {
+			return fmt.Errorf("parse api version %q: %v: %w", r.APIVersion, err, ErrInvalidObjectReference)
+		}
+	}
+
+	return nil
+}
+
+// BuildFrom builds the nodes within a number of hops of a root object. Edges are followed in
+// both directions. Only the objects reached from the root are visited, rather than the root's
+// namespace.
+func (n *NodeBuilder) BuildFrom(root ObjectReference, depth int) ([]GraphNode, error) {
+	object, err := resolveObjectReference(n.lister, root)
+	if err != nil {
+		return nil, err
+	}
+
+	objects, err := newSubgraphTraversal(n.lister, n.options...).reachable(object, depth)
+	if err != nil {
+		return nil, fmt.Errorf("traverse from %s: %w", root, err)
+	}
+
+	emitter, visitor, err := n.newVisitor()
+	if err != nil {
+		return nil, err
+	}
+
+	// the root is visited last so objects reached as groups keep their grouping.
+	if err := visitor.Visit(false, objects[1:]...); err != nil {
+		return nil, fmt.Errorf("visit objects: %w", err)
+	}
+
+	if err := visitor.Visit(false, object); err != nil {
+		return nil, fmt.Errorf("visit root: %w", err)
+	}
+
+	nodes, err := n.group(emitter)
+	if err != nil {
+		return nil, err
+	}
+
+	if spansNamespaces(emitter.Objects()) {
+		nodes, err = NewNamespaceGrouper().Group(nodes, emitter.Objects())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	seeds, err := subgraphSeeds(n.lister, object)
+	if err != nil {
+		return nil, err
+	}
+
+	return neighborhood(nodes, seeds, depth), nil
+}
+
+// spansNamespaces returns true if objects are in more than one namespace, counting cluster
+// scoped objects as a namespace.
+func spansNamespaces(objects map[string]*unstructured.Unstructured) bool {
+	namespaces := map[string]bool{}
+	for _, object := range objects {
+		namespaces[object.GetNamespace()] = true
+	}
+	return len(namespaces) > 1
+}
+
+// buildAround builds the graph surrounding root objects and returns the roots' node ids. Pods
+// summarized by their controller are not nodes, so their controller's id is returned instead. The
+// roots' namespaces are visited, or every namespace if a root is cluster scoped. It is used by
+// analyses that follow dependencies transitively, so they can't be limited to a traversal.
+func (n *NodeBuilder) buildAround(roots ...ObjectReference) ([]GraphNode, []string, error) {
+	var objects []*unstructured.Unstructured
+	var ids []string
+	var namespaces []string
+	clusterScoped := false
+
+	for _, root := range roots {
+		object, err := resolveObjectReference(n.lister, root)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		seeds, err := subgraphSeeds(n.lister, object)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		objects = append(objects, object)
+		ids = append(ids, seeds[0])
+
+		if namespace := object.GetNamespace(); namespace == "" {
+			clusterScoped = true
+		} else if !stringsIncludes(namespace, namespaces) {
+			namespaces = append(namespaces, namespace)
+		}
+	}
+
+	emitter, visitor, err := n.newVisitor()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	multipleNamespaces := clusterScoped || len(namespaces) > 1
+	switch {
+	case clusterScoped:
+		err = n.visitNamespaces(visitor, nil)
+	case multipleNamespaces:
+		err = n.visitNamespaces(visitor, namespaces)
+	default:
+		err = n.visitNamespace(visitor, namespaces[0])
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// roots are visited last so objects reached as groups keep their grouping.
+	if err := visitor.Visit(false, objects...); err != nil {
+		return nil, nil, fmt.Errorf("visit roots: %w", err)
+	}
+
+	nodes, err := n.group(emitter)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if multipleNamespaces {
+		nodes, err = NewNamespaceGrouper().Group(nodes, emitter.Objects())
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return nodes, ids, nil
+}
+
+// resolveObjectReference finds the object for a reference.
+func resolveObjectReference(lister Lister, ref ObjectReference) (*unstructured.Unstructured, error) {
+	if err := ref.Validate(); err != nil {
+		return nil, err
+	}
+
+	var gvks []schema.GroupVersionKind
+	if ref.Kind == "" {
+		var err error
+		gvks, err = uidLookupKinds(lister)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		gv, err := schema.ParseGroupVersion(ref.APIVersion)
+		if err != nil {
+			return nil, fmt.Errorf("parse api version %q: %w", ref.APIVersion, err)
+		}
+		gvks = []schema.GroupVersionKind{gv.WithKind(ref.Kind)}
+	}
+
+	if ref.Name != "" {
+		object, err := getObject(lister, gvks[0], ref.Namespace, ref.Name)
+		if err != nil {
+			if isUnavailable(err) {
+				return nil, fmt.Errorf("%s: %w", ref, ErrObjectNotFound)
+			}
+			return nil, fmt.Errorf("get %s: %w", ref, err)
+		}
+
+		if ref.UID != "" && string(object.GetUID()) != ref.UID {
+			return nil, fmt.Errorf("%s with uid %s: %w", ref, ref.UID, ErrObjectNotFound)
+		}
+
+		return object, nil
+	}
+
+	for _, gvk := range gvks {
+		var objects []*unstructured.Unstructured
+		var err error
+		if ref.Namespace == "" {
+			objects, err = lister.List(gvk, labels.Everything())
+		} else {
+			objects, err = lister.ByNamespace(ref.Namespace).List(gvk, labels.Everything())
+		}
+		if err != nil {
+			if isUnavailable(err) {
+				continue
+			}
+			return nil, fmt.Errorf("list %s: %w", gvk.Kind, err)
+		}
+
+		for _, object := range objects {
+			if string(object.GetUID()) == ref.UID {
+				return object, nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("%s: %w", ref, ErrObjectNotFound)
+}
+
+// uidLookupKinds returns the kinds to search for an object by uid. They are the kinds the lister
+// can list, so custom resources can be found.
+func uidLookupKinds(lister Lister) ([]schema.GroupVersionKind, error) {
+	kindLister, ok := lister.(KindLister)
+	if !ok {
+		return uidLookupGVKs, nil
+	}
+
+	kinds, err := kindLister.Kinds()
+	if err != nil {
+		return nil, fmt.Errorf("list kinds: %w", err)
+	}
+
+	if len(kinds) == 0 {
+		return uidLookupGVKs, nil
+	}
+
+	return kinds, nil
+}
@@ -0,0 +1,96 @@
+package rvnodegen
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// SubgraphHandler is a HTTP handler for building the graph around an object.
+type SubgraphHandler struct {
+	lister  Lister
+	options []Option
+}
+
+var _ http.Handler = &SubgraphHandler{}
+
+// NewSubgraphHandler creates an instance of SubgraphHandler.
+func NewSubgraphHandler(lister Lister, options ...Option) *SubgraphHandler {
+	h := &SubgraphHandler{
+		lister:  lister,
+		options: options,
+	}
+	return h
+}
+
+// ServeHTTP serves the handler. The root is identified with the apiVersion, kind, namespace,
+// name and uid query parameters, and depth limits how far the graph extends from it.
+func (h *SubgraphHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	root := objectReferenceFromQuery(r.URL.Query(), "")
+	if err := root.Validate(); err != nil {
+		respondWithError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	depth, err := depthFromQuery(r.URL.Query())
+	if err != nil {
+		respondWithError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	responseOptions, err := responseOptionsFromQuery(r.URL.Query())
+	if err != nil {
+		respondWithError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	nb := NewNodeBuilder(h.lister, h.options...)
+	nodes, err := nb.BuildFrom(root, depth)
+	if err != nil {
+		respondWithError(w, err, objectErrorStatus(err))
+		return
+	}
+
+	resp := nodesResponse{Nodes: responseOptions.Apply(nodes)}
+	respondWithJSON(w, resp, http.StatusOK)
+}
+
+// objectReferenceFromQuery creates an object reference from query parameters. The parameter
+// names are prefixed with prefix.
+func objectReferenceFromQuery(values url.Values, prefix string) ObjectReference {
+	return ObjectReference{
+		APIVersion: values.Get(prefix + "apiVersion"),
+		Kind:       values.Get(prefix + "kind"),
+		Namespace:  values.Get(prefix + "namespace"),
+		Name:       values.Get(prefix + "name"),
+		UID:        values.Get(prefix + "uid"),
+	}
+}
+
+func depthFromQuery(values url.Values) (int, error) {
+	value := values.Get("depth")
+	if value == "" {
+		return DefaultSubgraphDepth, nil
+	}
+
+	depth, err := strconv.Atoi(value)
+	if err != nil || depth < 0 {
+		return 0, fmt.Errorf("invalid depth %q", value)
+	}
+
+	return depth, nil
+}
+
+// objectErrorStatus returns the HTTP status for an error building from an object reference.
+func objectErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrInvalidObjectReference):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrObjectNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,69 @@
+package rvnodegen
+
+import (
+	"testing"
+)
+
+func TestNodeBuilder_BuildFrom(t *testing.T) {
+	application := newTestArgoApplication("argocd", "guestbook", "Healthy", "Synced")
+
+	pod := newTestObject(podGVK, "default", "guestbook")
+	pod.SetAnnotations(map[string]string{argoCDTrackingIDAnnotation: "guestbook:/Pod:default/guestbook"})
+	pod.Object["spec"] = map[string]interface{}{"serviceAccountName": "guestbook"}
+
+	serviceAccount := newTestObject(serviceAccountGVK, "default", "guestbook")
+	serviceAccount.Object["imagePullSecrets"] = []interface{}{
+		map[string]interface{}{"name": "registry"},
+	}
+
+	secret := newTestObject(secretGVK, "default", "registry")
+
+	resources := []fakeResource{
+		argoApplicationResource,
+		{gvk: serviceAccountGVK, resource: "serviceaccounts", namespaced: true},
+	}
+
+	lister := newFakeClusterLister(t, resources, application, pod, serviceAccount, secret)
+
+	tests := []struct {
+		name  string
+		root  ObjectReference
+		depth int
+		want  []string
+	}{
+		{
+			name:  "tracked object",
+			root:  objectReference(pod),
+			depth: 1,
+			want:  []string{string(application.GetUID()), string(serviceAccount.GetUID())},
+		},
+		{
+			name:  "application",
+			root:  objectReference(application),
+			depth: 1,
+			want:  []string{string(pod.GetUID())},
+		},
+		{
+			name:  "service account secrets",
+			root:  objectReference(pod),
+			depth: 2,
+			want:  []string{string(secret.GetUID())},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			nodes, err := NewNodeBuilder(lister).BuildFrom(test.root, test.depth)
+			if err != nil {
+				t.Fatalf("build from: %v", err)
+			}
+
+			byID := nodesByID(nodes)
+			for _, id := range test.want {
+				if _, ok := byID[id]; !ok {
+					t.Errorf("node %s was not emitted", id)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,238 @@
+package rvnodegen
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// subgraphReferrerGVKs are the kinds checked for references to an object when traversing a
+// subgraph. They are the kinds with owners or with references visitors follow.
+var subgraphReferrerGVKs = []schema.GroupVersionKind{
+	podGVK, replicaSetGVK, deploymentGVK, statefulSetGVK, daemonSetGVK, jobGVK, cronJobGVK,
+	replicationControllerGVK, serviceGVK, ingressGVK, serviceAccountGVK, configMapGVK, secretGVK,
+	persistentVolumeClaimGVK, persistentVolumeGVK, clusterRoleBindingGVK,
+}
+
+// subgraphTraversal finds the objects around a root object without visiting whole namespaces.
+// An object's neighbors are its owners, the objects it owns, the objects it references and the
+// objects that reference it. Pods summarized by their controller are traversed through without
+// counting as a hop, since they are not nodes in the graph.
+type subgraphTraversal struct {
+	lister              Lister
+	argoCD              *ArgoCDVisitor
+	flux                *FluxVisitor
+	argoCDInstanceLabel string
+	references          map[types.UID][]*unstructured.Unstructured
+}
+
+func newSubgraphTraversal(lister Lister, options ...Option) *subgraphTraversal {
+	t := &subgraphTraversal{
+		lister:              lister,
+		argoCD:              NewArgoCDVisitor(lister),
+		flux:                NewFluxVisitor(lister),
+		argoCDInstanceLabel: buildOptionConfig(options...).argoCDInstanceLabel,
+		references:          map[types.UID][]*unstructured.Unstructured{},
+	}
+	return t
+}
+
+// reachable returns the root and the objects up to a number of hops away from it.
+func (t *subgraphTraversal) reachable(root *unstructured.Unstructured, hops int) ([]*unstructured.Unstructured, error) {
+	reached := map[types.UID]bool{root.GetUID(): true}
+	out := []*unstructured.Unstructured{root}
+
+	frontier := []*unstructured.Unstructured{root}
+	for i := 0; i < hops && len(frontier) > 0; i++ {
+		var next []*unstructured.Unstructured
+
+		// summarized pods are appended to the frontier so they are traversed in this hop.
+		for j := 0; j < len(frontier); j++ {
+			neighbors, err := t.neighbors(frontier[j])
+			if err != nil {
+				return nil, err
+			}
+
+			for _, neighbor := range neighbors {
+				if reached[neighbor.GetUID()] {
+					continue
+				}
+				reached[neighbor.GetUID()] = true
+				out = append(out, neighbor)
+
+				summarized, err := isSummarizedPod(t.lister, neighbor)
+				if err != nil {
+					return nil, err
+				}
+
+				if summarized {
+					frontier = append(frontier, neighbor)
+				} else {
+					next = append(next, neighbor)
+				}
+			}
+		}
+
+		frontier = next
+	}
+
+	return out, nil
+}
+
+func (t *subgraphTraversal) neighbors(object *unstructured.Unstructured) ([]*unstructured.Unstructured, error) {
+	references, err := t.referencesOf(object)
+	if err != nil {
+		return nil, err
+	}
+
+	referrers, err := t.referrersOf(object)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(references, referrers...), nil
+}
+
+// referrersOf finds the objects that own or reference an object. Namespaced objects are only
+// referenced from their namespace and cluster scoped objects, except for GitOps objects, which
+// manage objects in any namespace.
+func (t *subgraphTraversal) referrersOf(object *unstructured.Unstructured) ([]*unstructured.Unstructured, error) {
+	var out []*unstructured.Unstructured
+
+	gitOps := isGroupKindMatch(object.GroupVersionKind().GroupKind(), gitOpsGVKs)
+
+	for _, gvk := range subgraphReferrerGVKs {
+		namespace, err := scopedNamespace(t.lister, gvk, object.GetNamespace())
+		if err != nil {
+			return nil, err
+		}
+
+		if gitOps {
+			namespace = ""
+		}
+
+		var candidates []*unstructured.Unstructured
+		if namespace == "" {
+			candidates, err = t.lister.List(gvk, labels.Everything())
+		} else {
+			candidates, err = t.lister.ByNamespace(namespace).List(gvk, labels.Everything())
+		}
+		if err != nil {
+			if isUnavailable(err) {
+				continue
+			}
+			return nil, fmt.Errorf("list %s: %w", gvk.Kind, err)
+		}
+
+		for _, candidate := range candidates {
+			references, err := t.referencesOf(candidate)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, reference := range references {
+				if reference.GetUID() == object.GetUID() {
+					out = append(out, candidate)
+					break
+				}
+			}
+		}
+	}
+
+	return out, nil
+}
+
+// referencesOf finds the objects an object's owner references, spec references, selector and
+// GitOps tracking point at. Objects that don't exist are skipped.
+func (t *subgraphTraversal) referencesOf(object *unstructured.Unstructured) ([]*unstructured.Unstructured, error) {
+	if references, ok := t.references[object.GetUID()]; ok {
+		return references, nil
+	}
+
+	var references []*unstructured.Unstructured
+	add := func(gvk schema.GroupVersionKind, namespace, name string) error {
+		if name == "" {
+			return nil
+		}
+
+		reference, err := getObject(t.lister, gvk, namespace, name)
+		if err != nil {
+			if isUnavailable(err) {
+				return nil
+			}
+			return fmt.Errorf("get %s %s: %w", gvk.Kind, name, err)
+		}
+
+		references = append(references, reference)
+		return nil
+	}
+
+	for _, ref := range object.GetOwnerReferences() {
+		gvk, err := ownerGVK(ref)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := add(gvk, object.GetNamespace(), ref.Name); err != nil {
+			return nil, err
+		}
+	}
+
+	edgeRefs, err := edgeReferences(object)
+	if err != nil {
+		return nil, fmt.Errorf("find references: %w", err)
+	}
+
+	for _, ref := range edgeRefs {
+		if err := add(ref.gvk, ref.namespace, ref.name); err != nil {
+			return nil, err
+		}
+	}
+
+	if object.GroupVersionKind() == serviceGVK {
+		pods, err := selectedPods(t.lister, object)
+		if err != nil {
+			return nil, err
+		}
+		references = append(references, pods...)
+	}
+
+	application, err := t.argoCD.trackingApplication(object, t.argoCDInstanceLabel)
+	if err != nil {
+		return nil, err
+	}
+
+	controller, err := t.flux.managingController(object)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, gitOpsObject := range []*unstructured.Unstructured{application, controller} {
+		if gitOpsObject != nil {
+			references = append(references, gitOpsObject)
+		}
+	}
+
+	t.references[object.GetUID()] = references
+
+	return references, nil
+}
+
+// subgraphSeeds returns the node ids a subgraph is built around. Pods summarized by their
+// controller are not nodes, so their controller is used instead.
+func subgraphSeeds(lister Lister, root *unstructured.Unstructured) ([]string, error) {
+	summarized, err := isSummarizedPod(lister, root)
+	if err != nil {
+		return nil, err
+	}
+
+	if summarized {
+		return []string{string(metav1.GetControllerOf(root).UID)}, nil
+	}
+
+	return []string{string(root.GetUID())}, nil
+}
@@ -0,0 +1,176 @@
+package rvnodegen
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"math"
+	"sort"
+)
+
+const svgMargin = 20
+
+// SVGGraphEncoder renders nodes as an SVG image. Nodes are boxes colored by health and labelled
+// with their kind and name, groups are containers and edges are arrows. Nodes are laid out with
+// a layered layout unless they already have layouts.
+type SVGGraphEncoder struct{}
+
+var _ GraphEncoder = &SVGGraphEncoder{}
+
+// Format is the name of the format.
+func (e *SVGGraphEncoder) Format() string {
+	return "svg"
+}
+
+// ContentType is the media type of the encoding.
+func (e *SVGGraphEncoder) ContentType() string {
+	return "image/svg+xml"
+}
+
+// TypedEdges returns true because edges are labeled with their relation.
+func (e *SVGGraphEncoder) TypedEdges() bool {
+	return true
+}
+
+// MetadataFields are the metadata fields used to label nodes.
+func (e *SVGGraphEncoder) MetadataFields() []MetadataField {
+	return []MetadataField{MetadataFieldKind}
+}
+
+// Encode writes the nodes to w.
+func (e *SVGGraphEncoder) Encode(w io.Writer, nodes []GraphNode) error {
+	for _, node := range nodes {
+		if node.Layout == nil {
+			nodes = NewLayeredLayout().Layout(nodes)
+			break
+		}
+	}
+
+	var width, height float64
+	for _, node := range nodes {
+		width = math.Max(width, node.Layout.X+node.Layout.Width)
+		height = math.Max(height, node.Layout.Y+node.Layout.Height)
+	}
+
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%.0f" height="%.0f" viewBox="%.0f %.0f %.0f %.0f" font-family="Helvetica, Arial, sans-serif">`+"\n",
+		width+2*svgMargin, height+2*svgMargin, -float64(svgMargin), -float64(svgMargin), width+2*svgMargin, height+2*svgMargin)
+	fmt.Fprintln(bw, `  <defs>`)
+	fmt.Fprintln(bw, `    <marker id="arrow" viewBox="0 0 10 10" refX="10" refY="5" markerWidth="8" markerHeight="8" orient="auto-start-reverse">`)
+	fmt.Fprintln(bw, `      <path d="M 0 0 L 10 5 L 0 10 z" fill="#616161"/>`)
+	fmt.Fprintln(bw, `    </marker>`)
+	fmt.Fprintln(bw, `  </defs>`)
+
+	tree := newGraphTree(nodes)
+
+	// containers are drawn outermost first so their members are drawn over them.
+	var groups []GraphNode
+	for _, node := range nodes {
+		if node.IsGroup != nil || len(tree.children[node.ID]) > 0 {
+			groups = append(groups, node)
+		}
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].Layout.Width*groups[i].Layout.Height > groups[j].Layout.Width*groups[j].Layout.Height
+	})
+
+	for _, group := range groups {
+		writeSVGGroup(bw, group)
+	}
+
+	byID := nodesByID(nodes)
+	for _, edge := range graphEdges(nodes) {
+		writeSVGEdge(bw, edge, byID[edge.Source], byID[edge.Target])
+	}
+
+	for _, node := range nodes {
+		if node.IsGroup != nil || len(tree.children[node.ID]) > 0 {
+			continue
+		}
+		writeSVGNode(bw, node)
+	}
+
+	fmt.Fprintln(bw, `</svg>`)
+
+	return bw.Flush()
+}
+
+func writeSVGGroup(w io.Writer, node GraphNode) {
+	l := node.Layout
+	colors := colorsForHealth(node.HealthStatus)
+
+	fmt.Fprintf(w, `  <g class="group">`+"\n")
+	fmt.Fprintf(w, `    <rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" rx="8" fill="#fafafa" stroke="%s" stroke-dasharray="6 3"/>`+"\n",
+		l.X, l.Y, l.Width, l.Height, colors.stroke)
+	fmt.Fprintf(w, `    <text x="%.1f" y="%.1f" font-size="12" font-weight="bold" fill="#424242">%s</text>`+"\n",
+		l.X+layoutGroupPadding/2, l.Y+layoutGroupHeader-6, html.EscapeString(svgNodeTitle(node)))
+	fmt.Fprintf(w, `  </g>`+"\n")
+}
+
+func writeSVGNode(w io.Writer, node GraphNode) {
+	l := node.Layout
+	colors := colorsForHealth(node.HealthStatus)
+
+	dash := ""
+	if node.HealthStatus == HealthStatusTypeMissing {
+		dash = ` stroke-dasharray="4 2"`
+	}
+
+	fmt.Fprintf(w, `  <g class="node">`+"\n")
+	fmt.Fprintf(w, `    <rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" rx="6" fill="%s" stroke="%s" stroke-width="1.5"%s/>`+"\n",
+		l.X, l.Y, l.Width, l.Height, colors.fill, colors.stroke, dash)
+
+	if kind := svgNodeKind(node); kind != "" {
+		fmt.Fprintf(w, `    <text x="%.1f" y="%.1f" font-size="9" fill="#616161">%s</text>`+"\n",
+			l.X+8, l.Y+13, html.EscapeString(kind))
+	}
+
+	fmt.Fprintf(w, `    <text x="%.1f" y="%.1f" font-size="12" fill="#212121">%s</text>`+"\n",
+		l.X+8, l.Y+l.Height-10, html.EscapeString(node.Label))
+	fmt.Fprintf(w, `  </g>`+"\n")
+}
+
+// writeSVGEdge draws an edge from the right side of the source to the left side of the target.
+func writeSVGEdge(w io.Writer, edge Edge, source, target GraphNode) {
+	s, t := source.Layout, target.Layout
+	if s == nil || t == nil {
+		return
+	}
+
+	x1, y1 := s.X+s.Width, s.Y+s.Height/2
+	x2, y2 := t.X, t.Y+t.Height/2
+	if x2 < x1 {
+		// targets behind their source are reached from the source's left side.
+		x1, x2 = s.X, t.X+t.Width
+	}
+
+	curve := math.Max(20, math.Abs(x2-x1)/2)
+	if x2 < x1 {
+		curve = -curve
+	}
+
+	fmt.Fprintf(w, `  <path d="M %.1f %.1f C %.1f %.1f, %.1f %.1f, %.1f %.1f" fill="none" stroke="#616161" stroke-width="1.2" marker-end="url(#arrow)"/>`+"\n",
+		x1, y1, x1+curve, y1, x2-curve, y2, x2, y2)
+
+	if edge.Relation != "" {
+		fmt.Fprintf(w, `  <text x="%.1f" y="%.1f" font-size="9" fill="#757575" text-anchor="middle">%s</text>`+"\n",
+			(x1+x2)/2, (y1+y2)/2-4, html.EscapeString(string(edge.Relation)))
+	}
+}
+
+// svgNodeKind is the kind shown above a node's name. Nodes without metadata show their node type.
+func svgNodeKind(node GraphNode) string {
+	if node.Metadata != nil && node.Metadata.Kind != "" {
+		return node.Metadata.Kind
+	}
+	return string(node.NodeType)
+}
+
+func svgNodeTitle(node GraphNode) string {
+	if kind := svgNodeKind(node); kind != "" {
+		return kind + ": " + node.Label
+	}
+	return node.Label
+}
@@ -0,0 +1,32 @@
+package rvnodegen
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// SVGHandler is a HTTP handler that renders a namespace graph as SVG. Graphs are served by a
+// node handler, so layouts are seeded from previous requests.
+type SVGHandler struct {
+	nodeHandler *NodeHandler
+	encoder     *SVGGraphEncoder
+}
+
+var _ http.Handler = &SVGHandler{}
+
+// NewSVGHandler creates an instance of SVGHandler.
+func NewSVGHandler(lister Lister, options ...Option) *SVGHandler {
+	h := &SVGHandler{
+		nodeHandler: NewNodeHandler(lister, options...),
+		encoder:     &SVGGraphEncoder{},
+	}
+	return h
+}
+
+// ServeHTTP serves the handler. The namespace is a path variable, and the query parameters are
+// the same as the node handler's.
+func (h *SVGHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	scope := Scope{Namespaces: []string{mux.Vars(r)["namespace"]}}
+	h.nodeHandler.serveScopeWithEncoder(w, r, scope, h.encoder)
+}
@@ -3,22 +3,25 @@ package rvnodegen
 import (
 	"fmt"
 
-	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/labels"
-	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/utils/pointer"
 )
 
 // Visitor visits resources and emits data.
 type Visitor struct {
-	emitter          Emitter
-	lister           Lister
-	resourceVisitors []ResourceVisitor
-	visitedCache     map[types.UID]bool
-	healthStatus     HealthStatuser
+	emitter               Emitter
+	lister                Lister
+	resourceVisitors      []ResourceVisitor
+	visitedCache          map[types.UID]bool
+	healthStatus          HealthStatuser
+	expandRevisionHistory bool
+	argoCDInstanceLabel   string
+	// namespaces are the namespaces cluster scoped objects are followed into. If empty, every
+	// namespace is followed.
+	namespaces []string
 }
 
 // NewVisitor creates an instance of a Visitor.
@@ -31,11 +34,13 @@ func NewVisitor(emitter Emitter, lister Lister, resourceVisitors []ResourceVisit
 	}
 
 	v := &Visitor{
-		emitter:          emitter,
-		lister:           lister,
-		resourceVisitors: resourceVisitors,
-		visitedCache:     map[types.UID]bool{},
-		healthStatus:     hs,
+		emitter:               emitter,
+		lister:                lister,
+		resourceVisitors:      resourceVisitors,
+		visitedCache:          map[types.UID]bool{},
+		healthStatus:          hs,
+		expandRevisionHistory: opts.expandRevisionHistory,
+		argoCDInstanceLabel:   opts.argoCDInstanceLabel,
 	}
 	return v, nil
 }
@@ -70,6 +75,11 @@ func (v *Visitor) Visit(isGroup bool, objects ...*unstructured.Unstructured) err
 			return fmt.Errorf("health status: %w", err)
 		}
 
+		metadata, err := newNodeMetadata(object)
+		if err != nil {
+			return fmt.Errorf("node metadata: %w", err)
+		}
+
 		node := GraphNode{
 			ID:           string(object.GetUID()),
 			Label:        object.GetName(),
@@ -78,13 +88,11 @@ func (v *Visitor) Visit(isGroup bool, objects ...*unstructured.Unstructured) err
 			IsGroup:      ig,
 			NodeType:     nodeType,
 			HealthStatus: healthStatus,
+			Metadata:     metadata,
 		}
 
 		node, err = v.visitOwners(object, node)
 		if err != nil {
-			if errors.IsNotFound(err) {
-				continue
-			}
 			return err
 		}
 
@@ -103,6 +111,18 @@ func (v *Visitor) Visit(isGroup bool, objects ...*unstructured.Unstructured) err
 			}
 		}
 
+		// pods with a controller are not emitted because they are summarized by their
+		// controller's node.
+		if isPod(object) {
+			summarized, err := isSummarizedPod(v.lister, object)
+			if err != nil {
+				return err
+			}
+			if summarized {
+				continue
+			}
+		}
+
 		if err := v.emitter.Emit(object, node); err != nil {
 			return fmt.Errorf("emit node: %w", err)
 		}
@@ -111,7 +131,17 @@ func (v *Visitor) Visit(isGroup bool, objects ...*unstructured.Unstructured) err
 	return nil
 }
 
+// inScope returns true if references from cluster scoped objects into a namespace are followed.
+func (v *Visitor) inScope(namespace string) bool {
+	return len(v.namespaces) == 0 || stringsIncludes(namespace, v.namespaces)
+}
+
 func (v *Visitor) checkForOwnedPods(object *unstructured.Unstructured, node GraphNode) (GraphNode, error) {
+	// pods can only be controlled by objects in their namespace.
+	if object.GetNamespace() == "" {
+		return node, nil
+	}
+
 	pods, err := v.lister.ByNamespace(object.GetNamespace()).List(podGVK, labels.Everything())
 	if err != nil {
 		return GraphNode{}, err
@@ -125,21 +155,19 @@ func (v *Visitor) checkForOwnedPods(object *unstructured.Unstructured, node Grap
 	}
 
 	if len(controlledPods) > 0 {
-		// node.Extra["podsOk"] = len(controlledPods)
-		// node.Keywords = append(node.Keywords, "podSummary")
-		pod := controlledPods[0]
-
-		serviceAccountName, _, err := unstructured.NestedString(pod.Object, "spec", "serviceAccount")
-
-		serviceAccount, err := v.lister.
-			ByNamespace(object.GetNamespace()).
-			Get(serviceAccountGVK, serviceAccountName)
+		summary, err := summarizePods(controlledPods)
 		if err != nil {
-			return GraphNode{}, err
+			return GraphNode{}, fmt.Errorf("summarize pods: %w", err)
 		}
-		node.Targets = append(node.Targets, string(serviceAccount.GetUID()))
 
-		if err := v.Visit(false, serviceAccount); err != nil {
+		if node.Extra == nil {
+			node.Extra = map[string]interface{}{}
+		}
+		node.Extra["podSummary"] = summary
+		node.HealthStatus = worseHealthStatus(node.HealthStatus, summary.HealthStatus())
+
+		node, err = visitPodSpecReferences(v, object, controlledPods[0], node)
+		if err != nil {
 			return GraphNode{}, err
 		}
 	}
@@ -149,23 +177,24 @@ func (v *Visitor) checkForOwnedPods(object *unstructured.Unstructured, node Grap
 
 func (v *Visitor) visitOwners(object *unstructured.Unstructured, node GraphNode) (GraphNode, error) {
 	for _, ref := range object.GetOwnerReferences() {
-		gv, err := schema.ParseGroupVersion(ref.APIVersion)
+		gvk, err := ownerGVK(ref)
 		if err != nil {
-			return GraphNode{}, fmt.Errorf("parse API version %q: %w", ref.APIVersion, err)
-		}
-
-		gvk := schema.GroupVersionKind{
-			Group:   gv.Group,
-			Version: gv.Version,
-			Kind:    ref.Kind,
+			return GraphNode{}, err
 		}
 
-		owner, err := v.lister.ByNamespace(object.GetNamespace()).Get(gvk, ref.Name)
+		owner, err := v.getReference(gvk, object.GetNamespace(), ref.Name)
 		if err != nil {
 			return GraphNode{}, fmt.Errorf("get owner: %w", err)
 		}
 
-		node = setTarget(owner, node)
+		if isPlaceholder(owner) {
+			node = addEdge(node, string(owner.GetUID()), EdgeRelationOwner, map[string]string{
+				"kind": ref.Kind,
+			}, newEdgeProvenance("Visitor", "metadata.ownerReferences", object, "is owned by", owner, ""))
+			continue
+		}
+
+		node = setTarget(object, owner, node)
 		n, isGroup := setParent(owner, node)
 		node = n
 
@@ -191,9 +220,11 @@ func setParent(owner *unstructured.Unstructured, node GraphNode) (GraphNode, boo
 	return node, isOwner
 }
 
-func setTarget(owner *unstructured.Unstructured, node GraphNode) GraphNode {
+func setTarget(object, owner *unstructured.Unstructured, node GraphNode) GraphNode {
 	if !ownsPods(owner) {
-		node.Targets = append(node.Targets, string(owner.GetUID()))
+		node = addEdge(node, string(owner.GetUID()), EdgeRelationOwner, map[string]string{
+			"kind": owner.GetKind(),
+		}, newEdgeProvenance("Visitor", "metadata.ownerReferences", object, "is owned by", owner, ""))
 	}
 
 	return node
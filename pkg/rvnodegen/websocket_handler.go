@@ -58,13 +58,16 @@ func ParseCommand(messageType int, data []byte) (Command, error) {
 // WebsocketHandler is a HTTP handler for handling web socket messages.
 type WebsocketHandler struct {
 	lister   Lister
+	clusters *ClusterManager
 	upgrader websocket.Upgrader
+	options  []Option
 }
 
 var _ http.Handler = &WebsocketHandler{}
 
-// NewWebsocketHandler creates an instance of WebsocketHandler.
-func NewWebsocketHandler(lister Lister) *WebsocketHandler {
+// NewWebsocketHandler creates an instance of WebsocketHandler. Commands with a cluster use the
+// cluster's lister from clusters, which is nil for a single cluster.
+func NewWebsocketHandler(lister Lister, clusters *ClusterManager, options ...Option) *WebsocketHandler {
 	upgrader := websocket.Upgrader{
 		CheckOrigin: func(r *http.Request) bool {
 			// TODO: this is not safe
@@ -73,14 +76,16 @@ func NewWebsocketHandler(lister Lister) *WebsocketHandler {
 	}
 	w := &WebsocketHandler{
 		lister:   lister,
+		clusters: clusters,
 		upgrader: upgrader,
+		options:  options,
 	}
 	return w
 }
 
 // ServeHTTP serves the handler.
 func (h *WebsocketHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	commands := CommandsFactory(h.lister)
+	commands := CommandsFactory(h.lister, h.clusters, h.options...)
 
 	logger := log.From(r.Context())
 